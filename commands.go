@@ -0,0 +1,403 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	probes "embedding-probes/probes"
+)
+
+// listCommand prints every registered task with its metric.
+func listCommand(args []string) error {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	configPath := fs.String("config", "config.json", "config file")
+	fs.Parse(args)
+
+	config, err := loadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+	if err := registerTasks(config); err != nil {
+		return err
+	}
+
+	var rows [][]string
+	for i, task := range probes.TaskRegistry {
+		metric := task.Metric()
+		control := ""
+		if metric.Control {
+			control = "yes"
+		}
+		rows = append(rows, []string{
+			fmt.Sprintf("%d", i+1),
+			task.Name(),
+			probes.TaskCategory(task),
+			metric.Name,
+			metric.Direction.String(),
+			fmt.Sprintf("[%g, %g]", metric.Min, metric.Max),
+			control,
+		})
+	}
+	printTable([]string{"Task", "Task Name", "Category", "Metric", "Direction", "Range", "Control"}, rows)
+	return nil
+}
+
+// embedLine is one line of embed's JSONL output.
+type embedLine struct {
+	Text      string    `json:"text"`
+	Dimension int       `json:"dimension"`
+	Embedding []float64 `json:"embedding"`
+}
+
+// embedCommand embeds each non-empty line of stdin with one configured model
+// and writes the vectors to stdout as JSON lines. The cache is not used.
+func embedCommand(args []string) error {
+	fs := flag.NewFlagSet("embed", flag.ExitOnError)
+	configPath := fs.String("config", "config.json", "config file")
+	modelName := fs.String("model", "", "model name or alias to embed with (default the only configured model)")
+	fs.Parse(args)
+
+	config, err := loadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+	models := config.Models
+	if *modelName != "" {
+		models, err = selectModels(append(models, baselineModels(models)...), *modelName)
+		if err != nil {
+			return err
+		}
+	} else if len(models) != 1 {
+		return fmt.Errorf("embed: %d models configured; choose one with -model", len(models))
+	}
+	embedders, err := newEmbedders(models)
+	if err != nil {
+		return fmt.Errorf("Error configuring models: %v", err)
+	}
+
+	var texts []string
+	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if line := scanner.Text(); line != "" {
+			texts = append(texts, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("Error reading input: %v", err)
+	}
+	if len(texts) == 0 {
+		return nil
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	embeddings, err := embedders[0].EmbedBatch(ctx, texts)
+	if err != nil {
+		return fmt.Errorf("Error embedding with %s: %v", embedders[0].Name(), err)
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	encoder := json.NewEncoder(out)
+	for i, text := range texts {
+		if err := encoder.Encode(embedLine{text, len(embeddings[i]), embeddings[i]}); err != nil {
+			return err
+		}
+	}
+	return out.Flush()
+}
+
+// compareCommand prints how each model's score changed between two saved
+// runs. Tasks are matched by name, since task numbers depend on the config.
+// With -baseline it also lists regressions and fails if there are any, for
+// CI; the run to check defaults to the newest one in the history.
+func compareCommand(args []string) error {
+	fs := flag.NewFlagSet("compare", flag.ExitOnError)
+	baselinePath := fs.String("baseline", "", "check the run against this saved run and fail on regressions")
+	tolerance := fs.Float64("tolerance", 0.01, "with -baseline, the largest drop in a score that is not a regression")
+	configPath := fs.String("config", "config.json", "config file, read for history_dir")
+	historyDir := fs.String("history-dir", "", "run history directory (default from config, else "+probes.DefaultHistoryDir+")")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %[1]s compare old.json new.json\n       %[1]s compare -baseline old.json [flags] [new.json]\n\nFlags:\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	var beforePath, afterPath string
+	switch {
+	case *baselinePath == "" && fs.NArg() == 2:
+		beforePath, afterPath = fs.Arg(0), fs.Arg(1)
+	case *baselinePath != "" && fs.NArg() == 1:
+		beforePath, afterPath = *baselinePath, fs.Arg(0)
+	case *baselinePath != "" && fs.NArg() == 0:
+		history, err := openHistory(*configPath, *historyDir)
+		if err != nil {
+			return err
+		}
+		if afterPath, err = history.Latest(); err != nil {
+			return err
+		}
+		beforePath = *baselinePath
+	default:
+		fs.Usage()
+		return fmt.Errorf("compare: expected two saved runs, or -baseline and at most one")
+	}
+	before, err := probes.LoadRun(beforePath)
+	if err != nil {
+		return err
+	}
+	after, err := probes.LoadRun(afterPath)
+	if err != nil {
+		return err
+	}
+
+	var rows [][]string
+	var winnerChanges, onlyBefore, onlyAfter []string
+	for _, old := range before.Tasks {
+		task, ok := after.Task(old.Name)
+		if !ok {
+			onlyBefore = append(onlyBefore, old.Name)
+			continue
+		}
+		if old.Cancelled || task.Cancelled {
+			continue
+		}
+		for _, name := range after.Models {
+			a, okA := old.Results[name]
+			b, okB := task.Results[name]
+			if !okA || !okB {
+				continue
+			}
+			rows = append(rows, []string{
+				task.Name, task.Metric.Name, name,
+				fmt.Sprintf("%.4f", a.Metric),
+				fmt.Sprintf("%.4f", b.Metric),
+				fmt.Sprintf("%+.4f", b.Metric-a.Metric),
+				scoreChange(task.Metric, a.Metric, b.Metric),
+			})
+		}
+		if old.Outcome.String() != task.Outcome.String() {
+			winnerChanges = append(winnerChanges, fmt.Sprintf("%s: %s -> %s", task.Name, old.Outcome, task.Outcome))
+		}
+	}
+	for _, task := range after.Tasks {
+		if _, ok := before.Task(task.Name); !ok {
+			onlyAfter = append(onlyAfter, task.Name)
+		}
+	}
+
+	fmt.Printf("Comparing %s (A) with %s (B):\n", beforePath, afterPath)
+	printRunProvenance("A", before)
+	printRunProvenance("B", after)
+	if before.ConfigHash != "" && after.ConfigHash != "" && before.ConfigHash != after.ConfigHash {
+		fmt.Println("The runs used different configs.")
+	}
+	for _, change := range probes.IdentityChanges(before, after) {
+		fmt.Printf("Model changed: %s\n", change)
+	}
+	printTable([]string{"Task Name", "Metric", "Model", "A", "B", "Change", ""}, rows)
+	if len(winnerChanges) > 0 {
+		fmt.Println("\nWinner changes:")
+		for _, change := range winnerChanges {
+			fmt.Println("  " + change)
+		}
+	}
+	for _, name := range onlyBefore {
+		fmt.Printf("Only in A: %s\n", name)
+	}
+	for _, name := range onlyAfter {
+		fmt.Printf("Only in B: %s\n", name)
+	}
+
+	if *baselinePath == "" {
+		return nil
+	}
+	regressions := probes.FindRegressions(before, after, *tolerance)
+	if len(regressions) == 0 {
+		fmt.Printf("\nNo regressions beyond %g.\n", *tolerance)
+		return nil
+	}
+	fmt.Printf("\nRegressions beyond %g:\n", *tolerance)
+	for _, regression := range regressions {
+		fmt.Println("  " + regression.String())
+	}
+	return fmt.Errorf("%d regressions against %s", len(regressions), beforePath)
+}
+
+// printRunProvenance prints when and from which commit and config a run was
+// made.
+func printRunProvenance(label string, record probes.RunRecord) {
+	commit := record.ProbesCommit
+	if commit == "" {
+		commit = "unknown"
+	}
+	fmt.Printf("  %s: started %s, commit %s, config %s\n", label, record.Started.Format(time.RFC3339), commit, record.ConfigHash)
+}
+
+// openHistory returns the run history named by -history-dir or the config.
+func openHistory(configPath, historyDir string) (*probes.History, error) {
+	// The config only supplies the directory, so a missing one is not an
+	// error when -history-dir is given.
+	var config Config
+	if historyDir == "" {
+		var err error
+		if config, err = loadConfig(configPath); err != nil {
+			return nil, err
+		}
+	}
+	return probes.NewHistory(resolveHistoryDir(historyDir, config)), nil
+}
+
+// historyCommand lists the runs in the history, oldest first.
+func historyCommand(args []string) error {
+	fs := flag.NewFlagSet("history", flag.ExitOnError)
+	configPath := fs.String("config", "config.json", "config file, read for history_dir")
+	historyDir := fs.String("history-dir", "", "run history directory (default from config, else "+probes.DefaultHistoryDir+")")
+	fs.Parse(args)
+
+	history, err := openHistory(*configPath, *historyDir)
+	if err != nil {
+		return err
+	}
+	paths, err := history.Runs()
+	if err != nil {
+		return err
+	}
+	if len(paths) == 0 {
+		fmt.Printf("No runs in history %s\n", history.Dir())
+		return nil
+	}
+	var rows [][]string
+	for _, path := range paths {
+		record, err := probes.LoadRun(path)
+		if err != nil {
+			return err
+		}
+		commit, dirty := strings.CutSuffix(record.ProbesCommit, "+dirty")
+		if len(commit) > 12 {
+			commit = commit[:12]
+		}
+		if dirty {
+			commit += "+dirty"
+		}
+		rows = append(rows, []string{
+			path,
+			record.Started.Format(time.RFC3339),
+			commit,
+			record.ConfigHash,
+			strings.Join(record.ModelNames(), ", "),
+			fmt.Sprintf("%d", len(record.Tasks)),
+		})
+	}
+	printTable([]string{"Run", "Started", "Commit", "Config", "Models", "Tasks"}, rows)
+	return nil
+}
+
+// scoreChange labels a change from a to b, ignoring differences within the
+// metric's tie tolerance.
+func scoreChange(metric probes.MetricDescriptor, a, b float64) string {
+	switch {
+	case metric.Better(b, a):
+		return "better"
+	case metric.Better(a, b):
+		return "worse"
+	default:
+		return ""
+	}
+}
+
+// reportCommand prints the results table of a saved run, or exports it in
+// another format.
+func reportCommand(args []string) error {
+	fs := flag.NewFlagSet("report", flag.ExitOnError)
+	format := fs.String("format", "table", "table, "+strings.Join(probes.ExportFormats, ", "))
+	output := fs.String("output", "", "write the report to this file instead of stdout")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s report [flags] run.json\n\nFlags:\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("report: expected one saved run")
+	}
+	record, err := probes.LoadRun(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	if *format != "table" {
+		if *output != "" {
+			return probes.SaveRun(*output, record, *format)
+		}
+		return probes.ExportRun(os.Stdout, record, *format)
+	}
+	if *output != "" {
+		return fmt.Errorf("report: -output needs a -format other than table")
+	}
+
+	fmt.Printf("Run started %s, took %s\n", record.Started.Format(time.RFC3339), record.Duration.Round(time.Millisecond))
+	if len(record.Baselines) > 0 {
+		fmt.Printf("Baselines: %v\n", record.Baselines)
+	}
+	printResultsTable(record)
+	printReliability(record)
+	return nil
+}
+
+// cacheCommand handles "cache clear" and "cache prune".
+func cacheCommand(args []string) error {
+	fs := flag.NewFlagSet("cache", flag.ExitOnError)
+	configPath := fs.String("config", "config.json", "config file")
+	cacheDir := fs.String("cache-dir", "", "embedding cache directory (default from config, else "+probes.DefaultCacheDir+")")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s cache [flags] clear|prune [-max-age duration]\n\nFlags:\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	// The config only supplies the cache directory, so a missing one is
+	// not an error when -cache-dir is given.
+	var config Config
+	if *cacheDir == "" {
+		var err error
+		if config, err = loadConfig(*configPath); err != nil {
+			return err
+		}
+	}
+	return runCacheCommand(probes.NewEmbeddingCache(resolveCacheDir(*cacheDir, config)), fs.Args())
+}
+
+// runCacheCommand runs a cache subcommand against cache.
+func runCacheCommand(cache *probes.EmbeddingCache, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("cache: expected clear or prune")
+	}
+	switch args[0] {
+	case "clear":
+		if err := cache.Clear(); err != nil {
+			return err
+		}
+		fmt.Printf("Cleared embedding cache %s\n", cache.Dir())
+	case "prune":
+		fs := flag.NewFlagSet("cache prune", flag.ExitOnError)
+		maxAge := fs.Duration("max-age", 30*24*time.Hour, "remove entries unused for longer than this")
+		fs.Parse(args[1:])
+		removed, err := cache.Prune(*maxAge)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Removed %d embeddings unused for %s from %s\n", removed, *maxAge, cache.Dir())
+	default:
+		return fmt.Errorf("cache: unknown command %q", args[0])
+	}
+	return nil
+}
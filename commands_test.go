@@ -0,0 +1,84 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	probes "embedding-probes/probes"
+)
+
+// TestMain runs the command line itself when a test re-executes the test
+// binary with PROBES_TEST_ARGS set, so tests can check its exit status.
+func TestMain(m *testing.M) {
+	if args := os.Getenv("PROBES_TEST_ARGS"); args != "" {
+		os.Args = append([]string{"embedding-probes"}, strings.Split(args, "\n")...)
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// runMain runs the command line with args in a child process and returns its
+// combined output and exit code.
+func runMain(t *testing.T, args ...string) (string, int) {
+	t.Helper()
+	cmd := exec.Command(os.Args[0])
+	cmd.Env = append(os.Environ(), "PROBES_TEST_ARGS="+strings.Join(args, "\n"))
+	out, err := cmd.CombinedOutput()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return string(out), exitErr.ExitCode()
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(out), 0
+}
+
+func TestCompareBaselineExitCode(t *testing.T) {
+	dir := t.TempDir()
+	save := func(name string, score float64) string {
+		path := filepath.Join(dir, name)
+		record := probes.RunRecord{
+			Models: []string{"m"},
+			Tasks: []probes.TaskRecord{{
+				Number: 1, Name: "Evidence", Metric: probes.MetricDescriptor{Name: "Accuracy", Min: 0, Max: 1},
+				Results: map[string]probes.TaskResult{"m": {Metric: score}},
+			}},
+		}
+		if err := probes.SaveRun(path, record, probes.FormatJSON); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	baseline := save("baseline.json", 0.8)
+	tests := []struct {
+		name      string
+		score     float64
+		tolerance string
+		code      int
+		output    string
+	}{
+		{"regression", 0.7, "0.01", 1, "1 regressions against " + baseline},
+		{"within tolerance", 0.75, "0.1", 0, "No regressions beyond 0.1."},
+		{"improvement", 0.9, "0.01", 0, "No regressions beyond 0.01."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current := save(tt.name+".json", tt.score)
+			out, code := runMain(t, "compare", "-baseline", baseline, "-tolerance", tt.tolerance, current)
+			if code != tt.code || !strings.Contains(out, tt.output) {
+				t.Errorf("exit code %d, want %d; output:\n%s", code, tt.code, out)
+			}
+		})
+	}
+
+	// Without -baseline, compare only reports.
+	if out, code := runMain(t, "compare", baseline, save("plain.json", 0.1)); code != 0 {
+		t.Errorf("plain compare exited %d; output:\n%s", code, out)
+	}
+}
@@ -0,0 +1,257 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	probes "embedding-probes/probes"
+)
+
+type Config struct {
+	Models   []ModelConfig `json:"models"`
+	CacheDir string        `json:"cache_dir,omitempty"`
+	// HistoryDir keeps every run; defaults to ".probe-history".
+	HistoryDir string `json:"history_dir,omitempty"`
+	// DatasetsDir holds evidence dataset files; defaults to "datasets".
+	DatasetsDir    string                       `json:"datasets_dir,omitempty"`
+	AnalogyTasks   []probes.AnalogyTaskConfig   `json:"analogy_tasks,omitempty"`
+	PairTasks      []probes.PairTaskConfig      `json:"pair_tasks,omitempty"`
+	RetrievalTasks []probes.RetrievalTaskConfig `json:"retrieval_tasks,omitempty"`
+	STSTasks       []probes.STSTaskConfig       `json:"sts_tasks,omitempty"`
+}
+
+func loadConfig(path string) (Config, error) {
+	var config Config
+	configPath, err := filepath.Abs(path)
+	if err != nil {
+		return config, fmt.Errorf("Error resolving config path: %v", err)
+	}
+	configData, err := os.ReadFile(configPath)
+	if err != nil {
+		return config, fmt.Errorf("Error reading config file: %v", err)
+	}
+	if err := json.Unmarshal(configData, &config); err != nil {
+		return config, fmt.Errorf("Error parsing config file: %v", err)
+	}
+	config.resolvePaths(filepath.Dir(configPath))
+	return config, nil
+}
+
+// resolvePaths makes the relative paths in c relative to dir, the directory
+// holding the config file, rather than the working directory. An unset
+// datasets directory becomes the default one next to the config.
+func (c *Config) resolvePaths(dir string) {
+	resolve := func(path *string) {
+		if *path != "" && !filepath.IsAbs(*path) {
+			*path = filepath.Join(dir, *path)
+		}
+	}
+	if c.DatasetsDir == "" {
+		c.DatasetsDir = probes.DefaultDatasetsDir
+	}
+	resolve(&c.DatasetsDir)
+	resolve(&c.CacheDir)
+	resolve(&c.HistoryDir)
+	for i := range c.AnalogyTasks {
+		resolve(&c.AnalogyTasks[i].Path)
+	}
+	for i := range c.PairTasks {
+		resolve(&c.PairTasks[i].File)
+	}
+	for i := range c.STSTasks {
+		resolve(&c.STSTasks[i].File)
+	}
+	for i := range c.RetrievalTasks {
+		task := &c.RetrievalTasks[i]
+		resolve(&task.Dir)
+		resolve(&task.Corpus)
+		resolve(&task.Queries)
+		resolve(&task.Qrels)
+	}
+}
+
+// redacted returns a copy of c without API keys or header values, for
+// saving alongside results.
+func (c Config) redacted() Config {
+	models := make([]ModelConfig, len(c.Models))
+	for i, model := range c.Models {
+		model.APIKey = ""
+		if model.Headers != nil {
+			headers := make(map[string]string, len(model.Headers))
+			for name := range model.Headers {
+				headers[name] = "REDACTED"
+			}
+			model.Headers = headers
+		}
+		models[i] = model
+	}
+	c.Models = models
+	return c
+}
+
+// ModelConfig describes one model entry in config.json. An entry may be a
+// plain model name, which selects the local Ollama backend, or an object.
+type ModelConfig struct {
+	Model          string            `json:"model"`
+	Alias          string            `json:"alias,omitempty"`
+	Backend        string            `json:"backend,omitempty"`
+	BaseURL        string            `json:"base_url,omitempty"`
+	APIKey         string            `json:"api_key,omitempty"`
+	APIKeyEnv      string            `json:"api_key_env,omitempty"`
+	Headers        map[string]string `json:"headers,omitempty"`
+	Timeout        string            `json:"timeout,omitempty"`
+	MaxRetries     int               `json:"max_retries,omitempty"`
+	KeepAlive      string            `json:"keep_alive,omitempty"`
+	MaxBatchSize   int               `json:"max_batch_size,omitempty"`
+	Dimensions     int               `json:"dimensions,omitempty"`
+	EncodingFormat string            `json:"encoding_format,omitempty"`
+	Prefix         string            `json:"prefix,omitempty"`
+}
+
+func (m *ModelConfig) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*m = ModelConfig{Model: name}
+		return nil
+	}
+
+	type plain ModelConfig
+	var entry plain
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return fmt.Errorf("model entry must be a string or an object: %v", err)
+	}
+	*m = ModelConfig(entry)
+	return nil
+}
+
+func (m ModelConfig) embedderConfig() (probes.EmbedderConfig, error) {
+	cfg := probes.EmbedderConfig{
+		Backend:        m.Backend,
+		BaseURL:        m.BaseURL,
+		Model:          m.Model,
+		Alias:          m.Alias,
+		APIKey:         m.APIKey,
+		Headers:        m.Headers,
+		KeepAlive:      m.KeepAlive,
+		MaxRetries:     m.MaxRetries,
+		MaxBatchSize:   m.MaxBatchSize,
+		Dimensions:     m.Dimensions,
+		EncodingFormat: m.EncodingFormat,
+		Prefix:         m.Prefix,
+	}
+	if m.APIKeyEnv != "" {
+		key := os.Getenv(m.APIKeyEnv)
+		if key == "" {
+			return cfg, fmt.Errorf("model %s: environment variable %s is not set", m.Model, m.APIKeyEnv)
+		}
+		cfg.APIKey = key
+	}
+	if m.Timeout != "" {
+		timeout, err := time.ParseDuration(m.Timeout)
+		if err != nil {
+			return cfg, fmt.Errorf("model %s: invalid timeout %q: %v", m.Model, m.Timeout, err)
+		}
+		cfg.Timeout = timeout
+	}
+	return cfg, nil
+}
+
+// baselineModels returns the random and hashing baselines that models does
+// not already configure.
+func baselineModels(models []ModelConfig) []ModelConfig {
+	configured := make(map[string]bool)
+	for _, model := range models {
+		configured[model.Backend] = true
+	}
+	var baselines []ModelConfig
+	for _, backend := range []string{probes.BackendRandom, probes.BackendHashing} {
+		if !configured[backend] {
+			baselines = append(baselines, ModelConfig{Backend: backend})
+		}
+	}
+	return baselines
+}
+
+// name returns the display name of the embedder the entry configures.
+func (m ModelConfig) name() string {
+	return probes.EmbedderConfig{Backend: m.Backend, Model: m.Model, Alias: m.Alias}.DisplayName()
+}
+
+// selectModels keeps the entries named in a comma-separated list of model
+// names or aliases, in config order. An empty list keeps every entry.
+func selectModels(models []ModelConfig, names string) ([]ModelConfig, error) {
+	wanted := make(map[string]bool)
+	for _, name := range strings.Split(names, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			wanted[name] = true
+		}
+	}
+	if len(wanted) == 0 {
+		return models, nil
+	}
+	var selected []ModelConfig
+	found := make(map[string]bool)
+	for _, model := range models {
+		if wanted[model.name()] {
+			selected = append(selected, model)
+			found[model.name()] = true
+		}
+	}
+	for _, name := range strings.Split(names, ",") {
+		if name = strings.TrimSpace(name); name != "" && !found[name] {
+			return nil, fmt.Errorf("no configured model is named %q", name)
+		}
+	}
+	return selected, nil
+}
+
+// resolveCacheDir returns the cache directory given by flag, else by the
+// config, else the default.
+func resolveCacheDir(flagValue string, config Config) string {
+	if flagValue != "" {
+		return flagValue
+	}
+	if config.CacheDir != "" {
+		return config.CacheDir
+	}
+	return probes.DefaultCacheDir
+}
+
+// resolveHistoryDir returns the history directory given by flag, else by
+// the config, else the default.
+func resolveHistoryDir(flagValue string, config Config) string {
+	if flagValue != "" {
+		return flagValue
+	}
+	if config.HistoryDir != "" {
+		return config.HistoryDir
+	}
+	return probes.DefaultHistoryDir
+}
+
+// newEmbedders builds one embedder per configured model, rejecting entries
+// whose display names collide.
+func newEmbedders(models []ModelConfig) ([]probes.Embedder, error) {
+	embedders := make([]probes.Embedder, 0, len(models))
+	seen := make(map[string]bool)
+	for _, model := range models {
+		cfg, err := model.embedderConfig()
+		if err != nil {
+			return nil, err
+		}
+		embedder, err := probes.NewEmbedder(cfg)
+		if err != nil {
+			return nil, err
+		}
+		if seen[embedder.Name()] {
+			return nil, fmt.Errorf("duplicate model name %q; set a distinct alias", embedder.Name())
+		}
+		seen[embedder.Name()] = true
+		embedders = append(embedders, embedder)
+	}
+	return embedders, nil
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	probes "embedding-probes/probes"
+)
+
+func TestModelConfigUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want []ModelConfig
+		err  string
+	}{
+		{"plain string", `["nomic-embed-text"]`, []ModelConfig{{Model: "nomic-embed-text"}}, ""},
+		{
+			name: "object",
+			json: `[{"model": "text-embedding-3-small", "alias": "oai", "backend": "openai", "base_url": "https://api.example.com",
+				"api_key_env": "KEY", "headers": {"X-Team": "probes"}, "timeout": "30s", "max_retries": -1,
+				"max_batch_size": 16, "dimensions": 256, "encoding_format": "base64", "prefix": "q: "}]`,
+			want: []ModelConfig{{
+				Model: "text-embedding-3-small", Alias: "oai", Backend: "openai", BaseURL: "https://api.example.com",
+				APIKeyEnv: "KEY", Headers: map[string]string{"X-Team": "probes"}, Timeout: "30s", MaxRetries: -1,
+				MaxBatchSize: 16, Dimensions: 256, EncodingFormat: "base64", Prefix: "q: ",
+			}},
+		},
+		{
+			name: "mixed list",
+			json: `["granite-embedding", {"model": "nomic-embed-text", "base_url": "http://gpu-box:11434", "keep_alive": "10m"}, "all-minilm"]`,
+			want: []ModelConfig{
+				{Model: "granite-embedding"},
+				{Model: "nomic-embed-text", BaseURL: "http://gpu-box:11434", KeepAlive: "10m"},
+				{Model: "all-minilm"},
+			},
+		},
+		{"number", `[42]`, nil, "model entry must be a string or an object"},
+		{"wrong field type", `[{"model": "m", "max_retries": "3"}]`, nil, "model entry must be a string or an object"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var models []ModelConfig
+			err := json.Unmarshal([]byte(tt.json), &models)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("err = %v, want it to contain %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(models, tt.want) {
+				t.Errorf("models = %+v, want %+v", models, tt.want)
+			}
+		})
+	}
+}
+
+func TestModelConfigEmbedderConfig(t *testing.T) {
+	t.Setenv("PROBES_TEST_KEY", "from-env")
+	t.Setenv("PROBES_TEST_EMPTY", "")
+	tests := []struct {
+		name  string
+		model ModelConfig
+		want  probes.EmbedderConfig
+		err   string
+	}{
+		{
+			name:  "literal key",
+			model: ModelConfig{Model: "m", Backend: "openai", APIKey: "literal"},
+			want:  probes.EmbedderConfig{Model: "m", Backend: "openai", APIKey: "literal"},
+		},
+		{
+			name:  "key from environment",
+			model: ModelConfig{Model: "m", Backend: "openai", APIKeyEnv: "PROBES_TEST_KEY"},
+			want:  probes.EmbedderConfig{Model: "m", Backend: "openai", APIKey: "from-env"},
+		},
+		{
+			name:  "environment wins over literal",
+			model: ModelConfig{Model: "m", APIKey: "literal", APIKeyEnv: "PROBES_TEST_KEY"},
+			want:  probes.EmbedderConfig{Model: "m", APIKey: "from-env"},
+		},
+		{
+			name:  "timeout",
+			model: ModelConfig{Model: "m", Timeout: "45s", MaxRetries: 2},
+			want:  probes.EmbedderConfig{Model: "m", Timeout: 45 * time.Second, MaxRetries: 2},
+		},
+		{name: "unset variable", model: ModelConfig{Model: "m", APIKeyEnv: "PROBES_TEST_UNSET"}, err: "environment variable PROBES_TEST_UNSET is not set"},
+		{name: "empty variable", model: ModelConfig{Model: "m", APIKeyEnv: "PROBES_TEST_EMPTY"}, err: "environment variable PROBES_TEST_EMPTY is not set"},
+		{name: "invalid timeout", model: ModelConfig{Model: "m", Timeout: "soon"}, err: `model m: invalid timeout "soon"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := tt.model.embedderConfig()
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("err = %v, want it to contain %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(cfg, tt.want) {
+				t.Errorf("config = %+v, want %+v", cfg, tt.want)
+			}
+		})
+	}
+}
@@ -1,141 +1,377 @@
 package main
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"os"
+	"os/exec"
+	"os/signal"
+	"path"
 	"path/filepath"
+	"regexp"
+	"runtime"
+	"runtime/debug"
+	"slices"
+	"strconv"
 	"strings"
+	"syscall"
+	"time"
 
 	probes "embedding-probes/probes"
 )
 
-type Config struct {
-	Models []string `json:"models"`
+// identityTimeout bounds the model identity lookups at the end of a run.
+const identityTimeout = 10 * time.Second
+
+const usage = `Usage: %[1]s [command] [flags]
+
+Commands:
+  run      Run tasks against the configured models (the default)
+  list     List the registered tasks
+  embed    Embed lines read from stdin with one configured model
+  compare  Compare two saved runs
+  report   Print the results of a saved run
+  history  List the runs kept in the history
+  cache    Clear or prune the embedding cache
+
+Run "%[1]s <command> -h" for the flags of a command.
+`
+
+func main() {
+	args := os.Args[1:]
+	command := "run"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
+	}
+
+	var err error
+	switch command {
+	case "run":
+		err = runCommand(args)
+	case "list":
+		err = listCommand(args)
+	case "embed":
+		err = embedCommand(args)
+	case "compare":
+		err = compareCommand(args)
+	case "report":
+		err = reportCommand(args)
+	case "history":
+		err = historyCommand(args)
+	case "cache":
+		err = cacheCommand(args)
+	case "help":
+		fmt.Printf(usage, os.Args[0])
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown command %q\n\n", command)
+		fmt.Fprintf(os.Stderr, usage, os.Args[0])
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
 }
 
-func max(a, b int) int {
-	if a > b {
-		return a
+// registerTasks registers every task declared in config or found in its
+// datasets directory, after the tasks written in Go.
+func registerTasks(config Config) error {
+	datasetsDir := config.DatasetsDir
+	if datasetsDir == "" {
+		datasetsDir = probes.DefaultDatasetsDir
 	}
-	return b
+	if err := probes.RegisterAnalogyTasks(config.AnalogyTasks); err != nil {
+		return fmt.Errorf("Error loading analogy tasks: %v", err)
+	}
+	if err := probes.RegisterPairTasks(config.PairTasks); err != nil {
+		return fmt.Errorf("Error loading pair tasks: %v", err)
+	}
+	if err := probes.RegisterSTSTasks(config.STSTasks); err != nil {
+		return fmt.Errorf("Error loading STS tasks: %v", err)
+	}
+	if err := probes.RegisterRetrievalTasks(config.RetrievalTasks); err != nil {
+		return fmt.Errorf("Error loading retrieval tasks: %v", err)
+	}
+	if err := probes.LoadEvidenceDatasets(datasetsDir); err != nil {
+		return fmt.Errorf("Error loading datasets: %v", err)
+	}
+	return nil
 }
 
-func main() {
-	// Debug: Print registered tasks
-	fmt.Printf("Registered tasks: %d\n", len(probes.TaskRegistry))
-	for i, task := range probes.TaskRegistry {
-		fmt.Printf("Task %d: %s\n", i+1, task.Name())
+// selectTasks returns the registry numbers of the tasks matched by a
+// comma-separated list of task numbers, case-insensitive name globs and
+// /regexp/ patterns, in registry order. An empty list selects every task.
+func selectTasks(patterns string) ([]int, error) {
+	selected := make(map[int]bool)
+	for _, pattern := range strings.Split(patterns, ",") {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		var match func(num int, name string) bool
+		if n, err := strconv.Atoi(pattern); err == nil {
+			match = func(num int, _ string) bool { return num == n }
+		} else if len(pattern) > 1 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+			re, err := regexp.Compile(pattern[1 : len(pattern)-1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid task pattern %s: %v", pattern, err)
+			}
+			match = func(_ int, name string) bool { return re.MatchString(name) }
+		} else {
+			glob := strings.ToLower(pattern)
+			if _, err := path.Match(glob, ""); err != nil {
+				return nil, fmt.Errorf("invalid task pattern %q: %v", pattern, err)
+			}
+			match = func(_ int, name string) bool {
+				ok, _ := path.Match(glob, strings.ToLower(name))
+				return ok
+			}
+		}
+		found := false
+		for i, task := range probes.TaskRegistry {
+			if match(i+1, task.Name()) {
+				selected[i+1] = true
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("no task matches %q", pattern)
+		}
 	}
 
-	configPath, err := filepath.Abs("config.json")
+	var nums []int
+	for i := range probes.TaskRegistry {
+		if len(selected) == 0 || selected[i+1] {
+			nums = append(nums, i+1)
+		}
+	}
+	return nums, nil
+}
+
+func runCommand(args []string) error {
+	fs := flag.NewFlagSet("run", flag.ExitOnError)
+	configPath := fs.String("config", "config.json", "config file")
+	taskPatterns := fs.String("tasks", "", "comma-separated task numbers, name globs or /regexp/ patterns to run (default all)")
+	modelNames := fs.String("models", "", "comma-separated model names or aliases to run (default all)")
+	output := fs.String("output", "", "save the run to this file")
+	format := fs.String("format", probes.FormatJSON, "format of the -output file: "+strings.Join(probes.ExportFormats, ", "))
+	noCache := fs.Bool("no-cache", false, "bypass the embedding cache")
+	cacheDir := fs.String("cache-dir", "", "embedding cache directory (default from config, else "+probes.DefaultCacheDir+")")
+	noBaselines := fs.Bool("no-baselines", false, "do not add the random and hashing baseline embedders")
+	historyDir := fs.String("history-dir", "", "run history directory (default from config, else "+probes.DefaultHistoryDir+")")
+	noHistory := fs.Bool("no-history", false, "do not save the run to the history")
+	fs.Parse(args)
+	if fs.NArg() > 0 {
+		return fmt.Errorf("run: unexpected argument %q", fs.Arg(0))
+	}
+	if !slices.Contains(probes.ExportFormats, *format) {
+		return fmt.Errorf("run: unknown format %q (want %s)", *format, strings.Join(probes.ExportFormats, ", "))
+	}
+
+	config, err := loadConfig(*configPath)
 	if err != nil {
-		fmt.Printf("Error resolving config path: %v\n", err)
-		return
+		return err
+	}
+	if err := registerTasks(config); err != nil {
+		return err
 	}
-	configData, err := os.ReadFile(configPath)
+	taskNums, err := selectTasks(*taskPatterns)
 	if err != nil {
-		fmt.Printf("Error reading config file: %v\n", err)
-		return
+		return err
 	}
-	var config Config
-	if err := json.Unmarshal(configData, &config); err != nil {
-		fmt.Printf("Error parsing config file: %v", err)
-		return
+
+	models, err := selectModels(config.Models, *modelNames)
+	if err != nil {
+		return err
+	}
+	if !*noBaselines {
+		models = append(models, baselineModels(config.Models)...)
+	}
+	embedders, err := newEmbedders(models)
+	if err != nil {
+		return fmt.Errorf("Error configuring models: %v", err)
 	}
 
-	results := make(map[int]map[string]probes.TaskResult)
-	for i, task := range probes.TaskRegistry {
-		taskNum := i + 1
+	// Baselines are computed locally, so caching them would only use disk.
+	var cache *probes.EmbeddingCache
+	if !*noCache {
+		cache = probes.NewEmbeddingCache(resolveCacheDir(*cacheDir, config))
+		for i, embedder := range embedders {
+			if !probes.IsBaseline(embedder) {
+				embedders[i] = cache.Wrap(embedder)
+			}
+		}
+	}
+
+	// The first SIGINT or SIGTERM cancels in-flight requests and stops
+	// scheduling tasks; completed results are still reported.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	record := probes.RunRecord{Started: time.Now(), Identities: make(map[string]probes.ModelIdentity)}
+	for _, embedder := range embedders {
+		record.Models = append(record.Models, embedder.Name())
+		if probes.IsBaseline(embedder) {
+			record.Baselines = append(record.Baselines, embedder.Name())
+		}
+	}
+	if record.Config, err = json.Marshal(config.redacted()); err != nil {
+		return fmt.Errorf("Error encoding config: %v", err)
+	}
+	record.ConfigHash = probes.ConfigHash(record.Config)
+	record.ProbesCommit = probesCommit()
+	if record.ProbesCommit == "" && !*noHistory {
+		fmt.Fprintln(os.Stderr, "Warning: could not determine the embedding-probes commit; this run is saved to the history without one")
+	}
+
+	cancelled := 0
+	for _, taskNum := range taskNums {
+		task := probes.TaskRegistry[taskNum-1]
+		taskRecord := probes.NewTaskRecord(taskNum, task)
+		if ctx.Err() != nil {
+			taskRecord.Cancelled = true
+			record.Tasks = append(record.Tasks, taskRecord)
+			cancelled++
+			continue
+		}
 		fmt.Printf("\nTask %d: %s\n", taskNum, task.Name())
-		taskResults, err := task.Run(config.Models)
+		taskResults, err := probes.RunTask(ctx, task, embedders)
 		if err != nil {
-			fmt.Printf("Error running task %d: %s: %v\n", taskNum, task.Name(), err)
-			return
+			if ctx.Err() != nil {
+				fmt.Printf("Task %d: %s cancelled\n", taskNum, task.Name())
+				taskRecord.Cancelled = true
+				record.Tasks = append(record.Tasks, taskRecord)
+				cancelled++
+				continue
+			}
+			return fmt.Errorf("Error running task %d: %s: %v", taskNum, task.Name(), err)
 		}
-		results[taskNum] = taskResults
+		printTaskSummary(embedders, taskResults)
+		taskRecord.Results = taskResults
+		taskRecord.Outcome = judgeTask(taskNum, task, taskResults, record)
+		record.Tasks = append(record.Tasks, taskRecord)
 	}
+	record.Duration = time.Since(record.Started)
 
-	// Calculate maximum widths for each column
-	maxTaskWidth := len("Task")
-	maxTaskNameWidth := len("Task Name")
-	maxMetricWidth := len("Metric")
-	maxGraniteWidth := len("granite-embedding:latest")
-	maxNomicWidth := len("nomic-embed-text")
-	maxWinnerWidth := len("Winner")
+	// Backends look identities up once and remember them, so this only
+	// reaches the server for models no task got to use. The lookups share
+	// a short deadline and stop at once after Ctrl-C, so a hung server
+	// cannot hold back the partial results; such models are recorded
+	// without an identity.
+	identityCtx, cancelIdentity := context.WithTimeout(ctx, identityTimeout)
+	for _, embedder := range embedders {
+		identity, err := embedder.Identity(identityCtx)
+		if err != nil {
+			fmt.Printf("Warning: could not identify %s: %v\n", embedder.Name(), err)
+			continue
+		}
+		record.Identities[embedder.Name()] = identity
+	}
+	cancelIdentity()
+	stop()
+	if cancelled > 0 {
+		fmt.Printf("\nRun interrupted: %d of %d tasks cancelled\n", cancelled, len(taskNums))
+	}
 
-	for taskNum := 1; taskNum <= len(results); taskNum++ {
-		task := probes.TaskRegistry[taskNum-1]
-		taskNumStr := fmt.Sprintf("%d", taskNum)
-		graniteMetric := fmt.Sprintf("%.4f", results[taskNum]["granite-embedding:latest"].Metric)
-		nomicMetric := fmt.Sprintf("%.4f", results[taskNum]["nomic-embed-text"].Metric)
-		winner := results[taskNum]["granite-embedding:latest"].Winner
-		if winner == "" {
-			winner = results[taskNum]["nomic-embed-text"].Winner
-		}
-		if winner == "" {
-			winner = "Tie"
-		}
-
-		maxTaskWidth = max(maxTaskWidth, len(taskNumStr))
-		maxTaskNameWidth = max(maxTaskNameWidth, len(task.Name()))
-		maxMetricWidth = max(maxMetricWidth, len(task.MetricName()))
-		maxGraniteWidth = max(maxGraniteWidth, len(graniteMetric))
-		maxNomicWidth = max(maxNomicWidth, len(nomicMetric))
-		maxWinnerWidth = max(maxWinnerWidth, len(winner))
-	}
-
-	// Print table with dynamic widths
-	fmt.Println("\nFinal Results Table:")
-	headerFormat := fmt.Sprintf("| %%-%ds | %%-%ds | %%-%ds | %%-%ds | %%-%ds | %%-%ds |",
-		maxTaskWidth, maxTaskNameWidth, maxMetricWidth, maxGraniteWidth, maxNomicWidth, maxWinnerWidth)
-	// Generate separator with proper width including borders and spaces
-	separator := fmt.Sprintf("|%%-%ds|%%-%ds|%%-%ds|%%-%ds|%%-%ds|%%-%ds|",
-		maxTaskWidth+2, maxTaskNameWidth+2, maxMetricWidth+2,
-		maxGraniteWidth+2, maxNomicWidth+2, maxWinnerWidth+2)
-	separator = fmt.Sprintf(separator,
-		strings.Repeat("-", maxTaskWidth),
-		strings.Repeat("-", maxTaskNameWidth),
-		strings.Repeat("-", maxMetricWidth),
-		strings.Repeat("-", maxGraniteWidth),
-		strings.Repeat("-", maxNomicWidth),
-		strings.Repeat("-", maxWinnerWidth))
-
-	fmt.Fprintf(os.Stdout, headerFormat+"\n", "Task", "Task Name", "Metric", "granite-embedding:latest", "nomic-embed-text", "Winner")
-	fmt.Println(separator)
-
-	for taskNum := 1; taskNum <= len(results); taskNum++ {
-		task := probes.TaskRegistry[taskNum-1]
-		graniteMetric := fmt.Sprintf("%.4f", results[taskNum]["granite-embedding:latest"].Metric)
-		nomicMetric := fmt.Sprintf("%.4f", results[taskNum]["nomic-embed-text"].Metric)
-		winner := results[taskNum]["granite-embedding:latest"].Winner
-		if winner == "" {
-			winner = results[taskNum]["nomic-embed-text"].Winner
-		}
-		if winner == "" {
-			winner = "Tie"
-		}
-		fmt.Fprintf(os.Stdout, headerFormat+"\n",
-			fmt.Sprintf("%d", taskNum), task.Name(), task.MetricName(), graniteMetric, nomicMetric, winner)
-	}
-
-	graniteWins := 0
-	nomicWins := 0
-	for taskNum := 1; taskNum <= len(results); taskNum++ {
-		if results[taskNum]["granite-embedding:latest"].Winner == "granite-embedding:latest" {
-			graniteWins++
-		} else if results[taskNum]["nomic-embed-text"].Winner == "nomic-embed-text" {
-			nomicWins++
-		}
-	}
-	fmt.Printf("\nOverall Reliability:\n")
-	fmt.Printf("granite-embedding:latest wins: %d\n", graniteWins)
-	fmt.Printf("nomic-embed-text wins: %d\n", nomicWins)
-	if graniteWins > nomicWins {
-		fmt.Printf("granite-embedding:latest is more reliable (%d vs. %d wins).\n", graniteWins, nomicWins)
-	} else if nomicWins > graniteWins {
-		fmt.Printf("nomic-embed-text is more reliable (%d vs. %d wins).\n", nomicWins, graniteWins)
-	} else {
-		fmt.Println("Both models are equally reliable.")
+	printResultsTable(record)
+	printReliability(record)
+
+	for _, embedder := range embedders {
+		if tokens := probes.TokensUsed(embedder); tokens > 0 {
+			fmt.Printf("%s used %d tokens\n", embedder.Name(), tokens)
+		}
+	}
+
+	if cache != nil {
+		hits, misses := cache.Stats()
+		fmt.Printf("\nEmbedding cache (%s): %d hits, %d misses\n", cache.Dir(), hits, misses)
+	}
+
+	if *output != "" {
+		if err := probes.SaveRun(*output, record, *format); err != nil {
+			return err
+		}
+		fmt.Printf("Saved run to %s\n", *output)
+	}
+	// A partial run would make a misleading baseline, so only complete runs
+	// join the history.
+	if !*noHistory && cancelled == 0 {
+		path, err := probes.NewHistory(resolveHistoryDir(*historyDir, config)).Save(record)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Saved run to history as %s\n", path)
+	}
+	if cancelled > 0 {
+		return fmt.Errorf("run interrupted")
+	}
+	return nil
+}
+
+// judgeTask picks the task's winner among the non-baseline models, tests its
+// significance and warns about out-of-range scores and baselines that match
+// the winner. Baselines get a column in the results table but never win.
+func judgeTask(taskNum int, task probes.Task, results map[string]probes.TaskResult, record probes.RunRecord) probes.Outcome {
+	metric := task.Metric()
+	for _, name := range record.Models {
+		if result, ok := results[name]; ok && !metric.InRange(result.Metric) {
+			fmt.Printf("Warning: task %d: %s: %s scored %.4f, outside the %s range [%g, %g]\n",
+				taskNum, task.Name(), name, result.Metric, metric.Name, metric.Min, metric.Max)
+		}
+	}
+
+	modelNames := record.ModelNames()
+	outcome := probes.SelectWinners(metric, results, modelNames)
+	if winner := outcome.Winner(); winner != "" {
+		for _, baseline := range record.Baselines {
+			if probes.SelectWinners(metric, results, []string{winner, baseline}).Winner() != winner {
+				fmt.Printf("Warning: task %d: %s: baseline %s scored %.4f, matching or beating %s (%.4f)\n",
+					taskNum, task.Name(), baseline, results[baseline].Metric, winner, results[winner].Metric)
+			}
+		}
+	}
+	return probes.TestSignificance(task, outcome, results, modelNames)
+}
+
+// probesCommit returns the git commit the binary was built from, with a
+// "+dirty" suffix for uncommitted changes, or "" if it cannot be found. The
+// Go toolchain stamps it into binaries built with "go build"; "go run"
+// binaries carry none, so git is asked about the directory this file was
+// compiled from, never the working directory, which may be another repo.
+func probesCommit() string {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		var revision, modified string
+		for _, setting := range info.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				revision = setting.Value
+			case "vcs.modified":
+				modified = setting.Value
+			}
+		}
+		if revision != "" {
+			if modified == "true" {
+				revision += "+dirty"
+			}
+			return revision
+		}
+	}
+	_, file, _, ok := runtime.Caller(0)
+	if !ok || !filepath.IsAbs(file) {
+		// Built with -trimpath, so the source directory is unknown.
+		return ""
+	}
+	dir := filepath.Dir(file)
+	out, err := exec.Command("git", "-C", dir, "rev-parse", "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	revision := strings.TrimSpace(string(out))
+	if status, err := exec.Command("git", "-C", dir, "status", "--porcelain", "--untracked-files=no").Output(); err == nil && len(bytes.TrimSpace(status)) > 0 {
+		revision += "+dirty"
 	}
+	return revision
 }
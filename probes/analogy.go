@@ -1,82 +1,407 @@
 package probes
 
 import (
+	"bufio"
+	"context"
 	"fmt"
-	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 )
 
-type analogyTask struct{}
+// DefaultAnalogyTopK is the k of the top-k accuracy when none is configured.
+const DefaultAnalogyTopK = 5
+
+// AnalogyTaskConfig declares an analogy benchmark in config.json.
+type AnalogyTaskConfig struct {
+	Name string `json:"name"`
+	// Path is a Google-format questions file, a single BATS category file,
+	// or a directory of BATS category files.
+	Path string `json:"path"`
+	// TopK defaults to DefaultAnalogyTopK.
+	TopK int `json:"top_k,omitempty"`
+}
+
+// AnalogyQuestion asks for the word that is to C as B is to A. Any of
+// Answers counts as correct.
+type AnalogyQuestion struct {
+	Section string
+	A, B, C string
+	Answers []string
+}
+
+// LoadAnalogies reads analogy questions from path.
+//
+// The Google format has ": section" headers followed by "a b c d" lines,
+// meaning a is to b as c is to d. BATS category files have one "word
+// target1/target2" pair per line; every ordered pair of distinct lines forms
+// a question, and the file name (without extension) is the section.
+func LoadAnalogies(path string) ([]AnalogyQuestion, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading analogies %s: %v", path, err)
+	}
+	if info.IsDir() {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, fmt.Errorf("error reading analogies %s: %v", path, err)
+		}
+		var questions []AnalogyQuestion
+		for _, entry := range entries {
+			if entry.IsDir() || !strings.EqualFold(filepath.Ext(entry.Name()), ".txt") {
+				continue
+			}
+			fileQuestions, err := loadBATSFile(filepath.Join(path, entry.Name()))
+			if err != nil {
+				return nil, err
+			}
+			questions = append(questions, fileQuestions...)
+		}
+		if len(questions) == 0 {
+			return nil, fmt.Errorf("no BATS category files in %s", path)
+		}
+		return questions, nil
+	}
+
+	lines, err := readAnalogyLines(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range lines {
+		if strings.HasPrefix(line.text, ":") || len(strings.Fields(line.text)) == 4 {
+			return parseGoogleAnalogies(path, lines)
+		}
+	}
+	return loadBATSFile(path)
+}
+
+type analogyLine struct {
+	num  int
+	text string
+}
+
+func readAnalogyLines(path string) ([]analogyLine, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading analogies %s: %v", path, err)
+	}
+	defer f.Close()
+
+	var lines []analogyLine
+	scanner := bufio.NewScanner(f)
+	num := 0
+	for scanner.Scan() {
+		num++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		lines = append(lines, analogyLine{num: num, text: text})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading analogies %s: %v", path, err)
+	}
+	return lines, nil
+}
+
+func parseGoogleAnalogies(path string, lines []analogyLine) ([]AnalogyQuestion, error) {
+	var questions []AnalogyQuestion
+	section := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	for _, line := range lines {
+		if strings.HasPrefix(line.text, ":") {
+			section = strings.TrimSpace(strings.TrimPrefix(line.text, ":"))
+			continue
+		}
+		words := strings.Fields(line.text)
+		if len(words) != 4 {
+			return nil, fmt.Errorf("%s:%d: expected 4 words, got %d", path, line.num, len(words))
+		}
+		questions = append(questions, AnalogyQuestion{
+			Section: section,
+			A:       words[0],
+			B:       words[1],
+			C:       words[2],
+			Answers: []string{words[3]},
+		})
+	}
+	if len(questions) == 0 {
+		return nil, fmt.Errorf("no analogy questions in %s", path)
+	}
+	return questions, nil
+}
+
+func loadBATSFile(path string) ([]AnalogyQuestion, error) {
+	lines, err := readAnalogyLines(path)
+	if err != nil {
+		return nil, err
+	}
+	section := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+
+	type pair struct {
+		source  string
+		targets []string
+	}
+	var pairs []pair
+	for _, line := range lines {
+		fields := strings.Fields(line.text)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%s:%d: expected a word and its targets, got %q", path, line.num, line.text)
+		}
+		pairs = append(pairs, pair{source: fields[0], targets: strings.Split(fields[1], "/")})
+	}
+
+	var questions []AnalogyQuestion
+	for i, first := range pairs {
+		for j, second := range pairs {
+			if i == j || first.source == second.source {
+				continue
+			}
+			questions = append(questions, AnalogyQuestion{
+				Section: section,
+				A:       first.source,
+				B:       first.targets[0],
+				C:       second.source,
+				Answers: second.targets,
+			})
+		}
+	}
+	if len(questions) == 0 {
+		return nil, fmt.Errorf("%s needs at least two word pairs", path)
+	}
+	return questions, nil
+}
+
+// RegisterAnalogyTasks loads each configured analogy benchmark and registers
+// a task for it.
+func RegisterAnalogyTasks(configs []AnalogyTaskConfig) error {
+	for _, cfg := range configs {
+		if cfg.Name == "" {
+			return fmt.Errorf("analogy task for %s has no name", cfg.Path)
+		}
+		questions, err := LoadAnalogies(cfg.Path)
+		if err != nil {
+			return err
+		}
+		if err := registerUnique(NewAnalogyTask(cfg.Name, questions, cfg.TopK)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// analogyTask answers each question by ranking every word in the benchmark's
+// vocabulary, excluding the three question words, with 3CosAdd and 3CosMul
+// (Levy and Goldberg, 2014). Both only depend on cosine similarities, so
+// scores are comparable across models with different vector norms.
+type analogyTask struct {
+	name      string
+	questions []AnalogyQuestion
+	topK      int
+	vocab     []string
+	sections  []string
+}
+
+// NewAnalogyTask returns a task reporting 3CosAdd top-1 accuracy, with
+// 3CosMul and top-k accuracies as sub-metrics.
+func NewAnalogyTask(name string, questions []AnalogyQuestion, topK int) Task {
+	if topK <= 0 {
+		topK = DefaultAnalogyTopK
+	}
+	t := &analogyTask{name: name, questions: questions, topK: topK}
+	seenWord := make(map[string]bool)
+	seenSection := make(map[string]bool)
+	for _, q := range questions {
+		for _, word := range append([]string{q.A, q.B, q.C}, q.Answers...) {
+			if !seenWord[word] {
+				seenWord[word] = true
+				t.vocab = append(t.vocab, word)
+			}
+		}
+		if !seenSection[q.Section] {
+			seenSection[q.Section] = true
+			t.sections = append(t.sections, q.Section)
+		}
+	}
+	return t
+}
 
 func (t *analogyTask) Name() string {
-	return "Analogy Task"
+	return t.name
+}
+
+func (t *analogyTask) Category() string {
+	return "Analogy"
 }
 
-func (t *analogyTask) MetricName() string {
-	return "Euclidean Distance"
+func (t *analogyTask) Metric() MetricDescriptor {
+	return MetricDescriptor{Name: "3CosAdd Top-1 Accuracy", Direction: HigherIsBetter, Min: 0, Max: 1}
 }
 
-func (t *analogyTask) Run(models []string) (map[string]TaskResult, error) {
-	terms := map[string]string{
-		"p": "Paris",
-		"f": "France",
-		"e": "England",
-		"l": "London",
+// MetricFromItems averages the per-question 3CosAdd top-1 hits held in item
+// scores.
+func (t *analogyTask) MetricFromItems(items []ItemResult) float64 {
+	return meanScore(items)
+}
+
+// analogyTally counts correct answers for one section.
+type analogyTally struct {
+	questions        int
+	addTop1, addTopK int
+	mulTop1, mulTopK int
+}
+
+func (t *analogyTask) Run(ctx context.Context, embedders []Embedder) (map[string]TaskResult, error) {
+	index := make(map[string]int, len(t.vocab))
+	for i, word := range t.vocab {
+		index[word] = i
 	}
 
 	results := make(map[string]TaskResult)
 
-	for _, model := range models {
+	for _, embedder := range embedders {
+		model := embedder.Name()
 		fmt.Printf("Model: %s\n", model)
-		embeddings := make(map[string][]float64)
 
-		for key, term := range terms {
-			emb, err := getEmbedding(model, term)
-			if err != nil {
-				return nil, fmt.Errorf("error getting embedding for %s: %v", term, err)
-			}
-			embeddings[key] = emb
+		embeddings, err := embedder.EmbedBatch(ctx, t.vocab)
+		if err != nil {
+			return nil, fmt.Errorf("error getting embeddings: %v", err)
 		}
-
-		if len(embeddings["p"]) != len(embeddings["f"]) || len(embeddings["f"]) != len(embeddings["e"]) || len(embeddings["e"]) != len(embeddings["l"]) {
-			return nil, fmt.Errorf("embedding dimensions do not match")
+		sims, err := similarityMatrix(embeddings)
+		if err != nil {
+			return nil, err
 		}
 
-		result := make([]float64, len(embeddings["p"]))
-		for i := 0; i < len(embeddings["p"]); i++ {
-			result[i] = embeddings["p"][i] - embeddings["f"][i] + embeddings["e"][i]
+		tallies := make(map[string]*analogyTally)
+		var total analogyTally
+		var items []ItemResult
+		for i, q := range t.questions {
+			a, b, c := index[q.A], index[q.B], index[q.C]
+			exclude := map[int]bool{a: true, b: true, c: true}
+			answers := make(map[int]bool)
+			for _, answer := range q.Answers {
+				answers[index[answer]] = true
+			}
+
+			addRanked := rankCandidates(len(t.vocab), exclude, func(x int) float64 {
+				return sims[x][b] - sims[x][a] + sims[x][c]
+			})
+			mulRanked := rankCandidates(len(t.vocab), exclude, func(x int) float64 {
+				// Shift cosines to [0, 1] so the product and ratio are
+				// well defined.
+				sa, sb, sc := (sims[x][a]+1)/2, (sims[x][b]+1)/2, (sims[x][c]+1)/2
+				return sb * sc / (sa + 1e-3)
+			})
+
+			tally := tallies[q.Section]
+			if tally == nil {
+				tally = &analogyTally{}
+				tallies[q.Section] = tally
+			}
+			addTop1, addTopK := hitsAt(addRanked, answers, 1), hitsAt(addRanked, answers, t.topK)
+			mulTop1, mulTopK := hitsAt(mulRanked, answers, 1), hitsAt(mulRanked, answers, t.topK)
+			for _, tl := range []*analogyTally{tally, &total} {
+				tl.questions++
+				tl.addTop1 += addTop1
+				tl.addTopK += addTopK
+				tl.mulTop1 += mulTop1
+				tl.mulTopK += mulTopK
+			}
+
+			// A vocabulary of only the question words leaves no candidate;
+			// the question counts as a miss with no prediction.
+			var predicted string
+			if len(addRanked) > 0 {
+				predicted = t.vocab[addRanked[0]]
+			}
+			items = append(items, ItemResult{
+				ID:        fmt.Sprintf("%s/%d", q.Section, i+1),
+				InputIDs:  []string{"a", "b", "c", "d"},
+				Texts:     []string{q.A, q.B, q.C, q.Answers[0]},
+				Score:     float64(addTop1),
+				Predicted: predicted,
+				Actual:    strings.Join(q.Answers, "/"),
+			})
 		}
 
-		distance := 0.0
-		for i := 0; i < len(result); i++ {
-			diff := result[i] - embeddings["l"][i]
-			distance += diff * diff
+		var subMetrics []SubMetric
+		subMetrics = append(subMetrics,
+			SubMetric{fmt.Sprintf("3CosAdd Top-%d Accuracy", t.topK), ratio(total.addTopK, total.questions)},
+			SubMetric{"3CosMul Top-1 Accuracy", ratio(total.mulTop1, total.questions)},
+			SubMetric{fmt.Sprintf("3CosMul Top-%d Accuracy", t.topK), ratio(total.mulTopK, total.questions)},
+		)
+		for _, section := range t.sections {
+			tally := tallies[section]
+			fmt.Printf("Section %s: 3CosAdd top-1=%.4f top-%d=%.4f, 3CosMul top-1=%.4f top-%d=%.4f (%d questions)\n",
+				section, ratio(tally.addTop1, tally.questions), t.topK, ratio(tally.addTopK, tally.questions),
+				ratio(tally.mulTop1, tally.questions), t.topK, ratio(tally.mulTopK, tally.questions), tally.questions)
+			subMetrics = append(subMetrics,
+				SubMetric{section + " 3CosAdd Top-1 Accuracy", ratio(tally.addTop1, tally.questions)},
+				SubMetric{fmt.Sprintf("%s 3CosAdd Top-%d Accuracy", section, t.topK), ratio(tally.addTopK, tally.questions)},
+				SubMetric{section + " 3CosMul Top-1 Accuracy", ratio(tally.mulTop1, tally.questions)},
+				SubMetric{fmt.Sprintf("%s 3CosMul Top-%d Accuracy", section, t.topK), ratio(tally.mulTopK, tally.questions)},
+			)
 		}
-		distance = math.Sqrt(distance)
 
-		fmt.Printf("Euclidean distance between (p - f + e) and l: %.4f\n", distance)
-		results[model] = TaskResult{Metric: distance}
+		accuracy := ratio(total.addTop1, total.questions)
+		fmt.Printf("3CosAdd top-1 accuracy: %.4f (%d/%d correct)\n", accuracy, total.addTop1, total.questions)
+		results[model] = TaskResult{Metric: accuracy, SubMetrics: subMetrics, Items: items}
+	}
+
+	return results, nil
+}
+
+// similarityMatrix returns the pairwise cosine similarities of embeddings.
+func similarityMatrix(embeddings [][]float64) ([][]float64, error) {
+	normalized, err := normalizeAll(embeddings)
+	if err != nil {
+		return nil, err
 	}
 
-	minDistance := math.MaxFloat64
-	var winner string
-	for model, result := range results {
-		if result.Metric < minDistance {
-			minDistance = result.Metric
-			winner = model
+	sims := make([][]float64, len(normalized))
+	for i := range normalized {
+		sims[i] = make([]float64, len(normalized))
+	}
+	for i := range normalized {
+		for j := i; j < len(normalized); j++ {
+			sim := dot(normalized[i], normalized[j])
+			sims[i][j], sims[j][i] = sim, sim
 		}
 	}
-	for model := range results {
-		if model == winner {
-			result := results[model]
-			result.Winner = model
-			results[model] = result
+	return sims, nil
+}
+
+// rankCandidates returns the indices in [0, n) not in exclude, best score
+// first. Equal scores keep vocabulary order.
+func rankCandidates(n int, exclude map[int]bool, score func(int) float64) []int {
+	candidates := make([]int, 0, n)
+	scores := make([]float64, n)
+	for x := 0; x < n; x++ {
+		if exclude[x] {
+			continue
 		}
+		candidates = append(candidates, x)
+		scores[x] = score(x)
 	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return scores[candidates[i]] > scores[candidates[j]]
+	})
+	return candidates
+}
 
-	return results, nil
+// hitsAt returns 1 if any of the first k ranked candidates is an answer.
+func hitsAt(ranked []int, answers map[int]bool, k int) int {
+	for i := 0; i < k && i < len(ranked); i++ {
+		if answers[ranked[i]] {
+			return 1
+		}
+	}
+	return 0
 }
 
-func init() {
-	fmt.Println("Registering Analogy Task")
-	RegisterTask(&analogyTask{})
+func ratio(n, d int) float64 {
+	if d == 0 {
+		return 0
+	}
+	return float64(n) / float64(d)
 }
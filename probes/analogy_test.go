@@ -0,0 +1,56 @@
+package probes
+
+import (
+	"context"
+	"testing"
+)
+
+func TestAnalogyNoCandidates(t *testing.T) {
+	// The vocabulary holds only the question words, so every candidate is
+	// excluded.
+	task := NewAnalogyTask("tiny", []AnalogyQuestion{{Section: "s", A: "x", B: "x", C: "y", Answers: []string{"y"}}}, 1)
+	embedder, err := NewBaselineEmbedder(EmbedderConfig{Backend: BackendHashing})
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := task.Run(context.Background(), []Embedder{embedder})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := results[embedder.Name()]
+	if result.Metric != 0 {
+		t.Errorf("Metric = %v, want 0", result.Metric)
+	}
+	if len(result.Items) != 1 || result.Items[0].Predicted != "" || result.Items[0].Score != 0 {
+		t.Errorf("items = %+v, want one miss with no prediction", result.Items)
+	}
+}
+
+func TestAnalogySectionSubMetrics(t *testing.T) {
+	questions := []AnalogyQuestion{
+		{Section: "capitals", A: "paris", B: "france", C: "rome", Answers: []string{"italy"}},
+		{Section: "plurals", A: "cat", B: "cats", C: "dog", Answers: []string{"dogs"}},
+	}
+	task := NewAnalogyTask("sections", questions, 3)
+	embedder, err := NewBaselineEmbedder(EmbedderConfig{Backend: BackendHashing})
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := task.Run(context.Background(), []Embedder{embedder})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]bool)
+	for _, sub := range results[embedder.Name()].SubMetrics {
+		got[sub.Name] = true
+	}
+	for _, section := range []string{"capitals", "plurals"} {
+		for _, method := range []string{"3CosAdd", "3CosMul"} {
+			for _, k := range []string{"Top-1", "Top-3"} {
+				if name := section + " " + method + " " + k + " Accuracy"; !got[name] {
+					t.Errorf("missing sub-metric %q", name)
+				}
+			}
+		}
+	}
+}
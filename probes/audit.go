@@ -0,0 +1,92 @@
+package probes
+
+import (
+	"fmt"
+	"math"
+)
+
+// SimilarityScorer is implemented by tasks whose ItemResult.Score is the
+// cosine similarity between the item's two Texts. The audit only checks
+// such scores for degenerate similarities.
+type SimilarityScorer interface {
+	ItemScoresAreSimilarities() bool
+}
+
+// auditTolerance is how close two similarities must be to count as equal,
+// and how close to 1 a similarity must be to count as a self-comparison.
+const auditTolerance = 1e-9
+
+// auditResult checks one model's result for signs that the task compared the
+// wrong inputs, and returns a failure for each problem found:
+//
+//   - an item's text was never passed to the embedder;
+//   - every item has the same similarity;
+//   - two different texts have a similarity of exactly 1;
+//   - every item got the same prediction although the actual labels differ.
+//
+// embedded holds the distinct texts the task embedded for this model.
+func auditResult(task Task, result TaskResult, embedded map[string]bool) []string {
+	var failures []string
+	for _, item := range result.Items {
+		for i, text := range item.Texts {
+			if !embedded[text] {
+				failures = append(failures, fmt.Sprintf("%s: input %s was never embedded (%q); the task compared something else",
+					item.ID, inputID(item, i), truncate(text, 60)))
+			}
+		}
+	}
+
+	if scorer, ok := task.(SimilarityScorer); ok && scorer.ItemScoresAreSimilarities() {
+		if len(result.Items) > 1 {
+			constant := true
+			for _, item := range result.Items[1:] {
+				if math.Abs(item.Score-result.Items[0].Score) > auditTolerance {
+					constant = false
+					break
+				}
+			}
+			if constant {
+				failures = append(failures, fmt.Sprintf("all %d items have similarity %.4f", len(result.Items), result.Items[0].Score))
+			}
+		}
+		for _, item := range result.Items {
+			if len(item.Texts) == 2 && item.Texts[0] != item.Texts[1] && item.Score >= 1-auditTolerance {
+				failures = append(failures, fmt.Sprintf("%s: distinct texts have similarity %.6f; the same text was probably embedded twice",
+					item.ID, item.Score))
+			}
+		}
+	}
+
+	if len(result.Items) > 1 && result.Items[0].Predicted != "" {
+		predicted, actual := result.Items[0].Predicted, result.Items[0].Actual
+		constant, mixed := true, false
+		for _, item := range result.Items[1:] {
+			if item.Predicted != predicted {
+				constant = false
+			}
+			if item.Actual != actual {
+				mixed = true
+			}
+		}
+		if constant && mixed {
+			failures = append(failures, fmt.Sprintf("all %d items were predicted %q although the actual labels differ; check the inputs and the threshold",
+				len(result.Items), predicted))
+		}
+	}
+	return failures
+}
+
+func inputID(item ItemResult, i int) string {
+	if i < len(item.InputIDs) {
+		return item.InputIDs[i]
+	}
+	return fmt.Sprintf("%d", i+1)
+}
+
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n]) + "..."
+}
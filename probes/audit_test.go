@@ -0,0 +1,34 @@
+package probes
+
+import "testing"
+
+// similarityTask is a task whose item scores are similarities.
+type similarityTask struct{ opaqueTask }
+
+func (similarityTask) ItemScoresAreSimilarities() bool { return true }
+
+func TestAuditResult(t *testing.T) {
+	embedded := map[string]bool{"q": true, "a": true, "b": true}
+	item := func(id, text string, score float64, predicted, actual string) ItemResult {
+		return ItemResult{ID: id, Texts: []string{"q", text}, Score: score, Predicted: predicted, Actual: actual}
+	}
+	tests := []struct {
+		name     string
+		items    []ItemResult
+		failures int
+	}{
+		{"clean", []ItemResult{item("1", "a", 0.8, "relevant", "relevant"), item("2", "b", 0.2, "irrelevant", "irrelevant")}, 0},
+		{"never embedded", []ItemResult{item("1", "a", 0.8, "", ""), item("2", "c", 0.2, "", "")}, 1},
+		{"distinct texts at 1", []ItemResult{item("1", "a", 1, "", ""), item("2", "b", 0.2, "", "")}, 1},
+		{"constant similarity", []ItemResult{item("1", "a", 0.5, "", ""), item("2", "b", 0.5, "", "")}, 1},
+		{"constant prediction", []ItemResult{item("1", "a", 0.4, "irrelevant", "relevant"), item("2", "b", 0.2, "irrelevant", "irrelevant")}, 1},
+		{"constant prediction and similarity", []ItemResult{item("1", "a", 0.3, "irrelevant", "relevant"), item("2", "b", 0.3, "irrelevant", "irrelevant")}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if failures := auditResult(similarityTask{}, TaskResult{Items: tt.items}, embedded); len(failures) != tt.failures {
+				t.Errorf("got failures %q, want %d", failures, tt.failures)
+			}
+		})
+	}
+}
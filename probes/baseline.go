@@ -0,0 +1,140 @@
+package probes
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"math/rand/v2"
+	"strings"
+)
+
+// DefaultBaselineDimension is the vector size of the baseline embedders when
+// EmbedderConfig.Dimensions is not set.
+const DefaultBaselineDimension = 512
+
+// hashingNGram is the character n-gram length of the hashing baseline.
+const hashingNGram = 3
+
+// baselineEmbedder is a negative control that needs no server: the random
+// baseline gives every distinct text an unrelated vector, so its scores show
+// what chance looks like, and the hashing baseline only sees surface
+// character overlap. Both are deterministic, so reruns agree.
+type baselineEmbedder struct {
+	cfg       EmbedderConfig
+	dimension int
+	embed     func(text string) ([]float64, error)
+}
+
+// NewBaselineEmbedder returns the random or hashing baseline selected by
+// cfg.Backend. The model name defaults to "random-baseline" or
+// "hashing-baseline".
+func NewBaselineEmbedder(cfg EmbedderConfig) (Embedder, error) {
+	if cfg.Model == "" {
+		cfg.Model = cfg.Backend + "-baseline"
+	}
+	e := &baselineEmbedder{cfg: cfg, dimension: cfg.Dimensions}
+	if e.dimension <= 0 {
+		e.dimension = DefaultBaselineDimension
+	}
+	switch cfg.Backend {
+	case BackendRandom:
+		e.embed = e.randomVector
+	case BackendHashing:
+		e.embed = e.hashedNGrams
+	default:
+		return nil, fmt.Errorf("unknown baseline backend %q", cfg.Backend)
+	}
+	return e, nil
+}
+
+// IsBaseline reports whether e, or the embedder it wraps, is a baseline.
+func IsBaseline(e Embedder) bool {
+	for {
+		if _, ok := e.(*baselineEmbedder); ok {
+			return true
+		}
+		wrapper, ok := e.(interface{ Unwrap() Embedder })
+		if !ok {
+			return false
+		}
+		e = wrapper.Unwrap()
+	}
+}
+
+func (e *baselineEmbedder) Name() string {
+	return e.cfg.DisplayName()
+}
+
+func (e *baselineEmbedder) Model() string {
+	return e.cfg.Model
+}
+
+func (e *baselineEmbedder) Dimension() int {
+	return e.dimension
+}
+
+func (e *baselineEmbedder) Identity(ctx context.Context) (ModelIdentity, error) {
+	return ModelIdentity{
+		Backend: e.cfg.Backend,
+		Model:   fmt.Sprintf("%s@%d", e.cfg.Model, e.dimension),
+		Prefix:  e.cfg.Prefix,
+	}, nil
+}
+
+func (e *baselineEmbedder) Embed(ctx context.Context, text string) ([]float64, error) {
+	embeddings, err := e.EmbedBatch(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+	return embeddings[0], nil
+}
+
+func (e *baselineEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float64, error) {
+	embeddings := make([][]float64, len(texts))
+	for i, text := range withPrefix(e.cfg.Prefix, texts) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		embedding, err := e.embed(text)
+		if err != nil {
+			return nil, err
+		}
+		embeddings[i] = embedding
+	}
+	return embeddings, nil
+}
+
+// randomVector draws a Gaussian vector seeded by a hash of the text.
+func (e *baselineEmbedder) randomVector(text string) ([]float64, error) {
+	sum := sha256.Sum256([]byte(text))
+	rng := rand.New(rand.NewPCG(binary.LittleEndian.Uint64(sum[:8]), binary.LittleEndian.Uint64(sum[8:16])))
+	vector := make([]float64, e.dimension)
+	for i := range vector {
+		vector[i] = rng.NormFloat64()
+	}
+	return vector, nil
+}
+
+// hashedNGrams counts the lower-cased character n-grams of the text, padded
+// with a space at each end, into hashed buckets with a hashed sign so that
+// collisions cancel out on average.
+func (e *baselineEmbedder) hashedNGrams(text string) ([]float64, error) {
+	runes := []rune(" " + strings.ToLower(text) + " ")
+	if len(runes) < hashingNGram {
+		return nil, fmt.Errorf("%w: text %q has no character %d-grams", ErrEmptyEmbedding, text, hashingNGram)
+	}
+	vector := make([]float64, e.dimension)
+	for i := 0; i+hashingNGram <= len(runes); i++ {
+		h := fnv.New64a()
+		h.Write([]byte(string(runes[i : i+hashingNGram])))
+		sum := h.Sum64()
+		sign := 1.0
+		if sum>>63 == 1 {
+			sign = -1
+		}
+		vector[sum%uint64(e.dimension)] += sign
+	}
+	return vector, nil
+}
@@ -0,0 +1,288 @@
+package probes
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultCacheDir is where embeddings are cached when no directory is
+// configured.
+const DefaultCacheDir = ".embedding-cache"
+
+// EmbeddingCache stores embeddings on disk, one file per vector, addressed by
+// a hash of the model identity and the text. Because the Ollama model digest
+// is part of the key, pulling a new version of a model under the same tag
+// misses the cache instead of returning stale vectors.
+type EmbeddingCache struct {
+	dir string
+
+	mu     sync.Mutex
+	hits   int
+	misses int
+}
+
+// NewEmbeddingCache returns a cache rooted at dir. The directory is created
+// on first write.
+func NewEmbeddingCache(dir string) *EmbeddingCache {
+	return &EmbeddingCache{dir: dir}
+}
+
+// Dir returns the cache's root directory.
+func (c *EmbeddingCache) Dir() string {
+	return c.dir
+}
+
+// Stats returns the number of cache hits and misses so far.
+func (c *EmbeddingCache) Stats() (hits, misses int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hits, c.misses
+}
+
+// Wrap returns an Embedder that serves texts from the cache and forwards
+// only the misses to e.
+func (c *EmbeddingCache) Wrap(e Embedder) Embedder {
+	return &cachedEmbedder{Embedder: e, cache: c}
+}
+
+// Clear removes every cached embedding and the shard directories they
+// leave empty. Only files the cache itself names are touched, so a cache
+// directory shared with other files, even ".", is safe to clear.
+func (c *EmbeddingCache) Clear() error {
+	shards := make(map[string]bool)
+	err := c.walkEntries(func(path string, _ fs.DirEntry) error {
+		shards[filepath.Dir(path)] = true
+		return os.Remove(path)
+	})
+	if err != nil {
+		return fmt.Errorf("error clearing cache %s: %v", c.dir, err)
+	}
+	for shard := range shards {
+		// A shard still holding other files is left in place.
+		os.Remove(shard)
+	}
+	return nil
+}
+
+// Prune removes entries that have not been read or written within maxAge and
+// returns how many were removed.
+func (c *EmbeddingCache) Prune(maxAge time.Duration) (int, error) {
+	cutoff := time.Now().Add(-maxAge)
+	removed := 0
+	err := c.walkEntries(func(path string, d fs.DirEntry) error {
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if info.ModTime().Before(cutoff) {
+			if err := os.Remove(path); err != nil {
+				return err
+			}
+			removed++
+		}
+		return nil
+	})
+	if err != nil {
+		return removed, fmt.Errorf("error pruning cache %s: %v", c.dir, err)
+	}
+	return removed, nil
+}
+
+// walkEntries calls fn for every cache entry, i.e. every file laid out as
+// path names it: <dir>/<first two hex digits>/<sha256 hex>.bin. Anything
+// else in the directory is ignored. A missing directory has no entries.
+func (c *EmbeddingCache) walkEntries(fn func(path string, d fs.DirEntry) error) error {
+	shards, err := os.ReadDir(c.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	for _, shard := range shards {
+		if !shard.IsDir() || len(shard.Name()) != 2 || !isLowerHex(shard.Name()) {
+			continue
+		}
+		shardDir := filepath.Join(c.dir, shard.Name())
+		entries, err := os.ReadDir(shardDir)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			key, ok := strings.CutSuffix(entry.Name(), ".bin")
+			if !ok || !entry.Type().IsRegular() || len(key) != sha256.Size*2 || !isLowerHex(key) || key[:2] != shard.Name() {
+				continue
+			}
+			if err := fn(filepath.Join(shardDir, entry.Name()), entry); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func isLowerHex(s string) bool {
+	for _, r := range s {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *EmbeddingCache) key(id ModelIdentity, text string) string {
+	h := sha256.New()
+	baseURL := ""
+	if id.Digest == "" {
+		// Without a digest the server is the only thing telling two
+		// same-named models apart.
+		baseURL = id.BaseURL
+	}
+	for _, part := range []string{id.Backend, baseURL, id.Model, id.Digest, id.Prefix, text} {
+		fmt.Fprintf(h, "%d:%s", len(part), part)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (c *EmbeddingCache) path(key string) string {
+	return filepath.Join(c.dir, key[:2], key+".bin")
+}
+
+func (c *EmbeddingCache) load(key string) ([]float64, bool) {
+	path := c.path(key)
+	data, err := os.ReadFile(path)
+	if err != nil || len(data) == 0 || len(data)%8 != 0 {
+		return nil, false
+	}
+	emb := make([]float64, len(data)/8)
+	for i := range emb {
+		emb[i] = math.Float64frombits(binary.LittleEndian.Uint64(data[i*8:]))
+	}
+	// Touch the entry so Prune keeps embeddings that are still in use.
+	now := time.Now()
+	os.Chtimes(path, now, now)
+	return emb, true
+}
+
+func (c *EmbeddingCache) store(key string, emb []float64) error {
+	path := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("error creating cache directory: %v", err)
+	}
+	data := make([]byte, len(emb)*8)
+	for i, v := range emb {
+		binary.LittleEndian.PutUint64(data[i*8:], math.Float64bits(v))
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("error writing cache entry: %v", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("error writing cache entry: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("error writing cache entry: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("error writing cache entry: %v", err)
+	}
+	return nil
+}
+
+func (c *EmbeddingCache) record(hits, misses int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.hits += hits
+	c.misses += misses
+}
+
+type cachedEmbedder struct {
+	Embedder
+	cache     *EmbeddingCache
+	dimension int
+}
+
+func (e *cachedEmbedder) Unwrap() Embedder {
+	return e.Embedder
+}
+
+func (e *cachedEmbedder) Dimension() int {
+	if d := e.Embedder.Dimension(); d > 0 {
+		return d
+	}
+	return e.dimension
+}
+
+func (e *cachedEmbedder) Embed(ctx context.Context, text string) ([]float64, error) {
+	embeddings, err := e.EmbedBatch(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+	return embeddings[0], nil
+}
+
+func (e *cachedEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float64, error) {
+	id, err := e.Identity(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	embeddings := make([][]float64, len(texts))
+	keys := make([]string, len(texts))
+	// Texts missing from the cache are embedded once even if repeated.
+	var missing []string
+	missingIdx := make(map[string]int)
+	hits := 0
+	for i, text := range texts {
+		keys[i] = e.cache.key(id, text)
+		if emb, ok := e.cache.load(keys[i]); ok {
+			embeddings[i] = emb
+			hits++
+			continue
+		}
+		if _, ok := missingIdx[text]; !ok {
+			missingIdx[text] = len(missing)
+			missing = append(missing, text)
+		}
+	}
+	e.cache.record(hits, len(missing))
+
+	if len(missing) > 0 {
+		fresh, err := e.Embedder.EmbedBatch(ctx, missing)
+		if err != nil {
+			return nil, err
+		}
+		for i, text := range texts {
+			if embeddings[i] != nil {
+				continue
+			}
+			emb := fresh[missingIdx[text]]
+			embeddings[i] = emb
+			if len(emb) == 0 {
+				continue
+			}
+			if err := e.cache.store(keys[i], emb); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if len(embeddings) > 0 && len(embeddings[0]) > 0 {
+		e.dimension = len(embeddings[0])
+	}
+	return embeddings, nil
+}
@@ -0,0 +1,167 @@
+package probes
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// batchRecorder records the batches passed to the wrapped embedder.
+type batchRecorder struct {
+	Embedder
+	batches [][]string
+}
+
+func (r *batchRecorder) EmbedBatch(ctx context.Context, texts []string) ([][]float64, error) {
+	r.batches = append(r.batches, append([]string(nil), texts...))
+	return r.Embedder.EmbedBatch(ctx, texts)
+}
+
+// writeFiles creates each path under dir with some content.
+func writeFiles(t *testing.T, dir string, paths ...string) {
+	t.Helper()
+	for _, path := range paths {
+		path = filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("keep"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestCacheClearOnlyRemovesEntries(t *testing.T) {
+	dir := t.TempDir()
+	cache := NewEmbeddingCache(dir)
+	key := cache.key(ModelIdentity{Backend: BackendOllama, Model: "m", Digest: "d"}, "text")
+	if err := cache.store(key, []float64{1, 2}); err != nil {
+		t.Fatal(err)
+	}
+	others := []string{
+		"main.go",
+		"notes/readme.bin",
+		key[:2] + "/notes.txt",
+		key[:2] + "/short.bin",
+		"zz/" + key + ".bin",
+	}
+	writeFiles(t, dir, others...)
+
+	if err := cache.Clear(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cache.load(key); ok {
+		t.Error("entry survived Clear")
+	}
+	for _, path := range others {
+		if _, err := os.Stat(filepath.Join(dir, path)); err != nil {
+			t.Errorf("Clear removed %s: %v", path, err)
+		}
+	}
+
+	// Once only entries are left, their shard directory goes too.
+	if err := cache.store(key, []float64{1, 2}); err != nil {
+		t.Fatal(err)
+	}
+	os.Remove(filepath.Join(dir, key[:2], "notes.txt"))
+	os.Remove(filepath.Join(dir, key[:2], "short.bin"))
+	if err := cache.Clear(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, key[:2])); !os.IsNotExist(err) {
+		t.Errorf("empty shard directory was kept: %v", err)
+	}
+	if _, err := os.Stat(dir); err != nil {
+		t.Errorf("Clear removed the cache root: %v", err)
+	}
+}
+
+func TestCacheClearMissingDir(t *testing.T) {
+	if err := NewEmbeddingCache(filepath.Join(t.TempDir(), "missing")).Clear(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCachePrune(t *testing.T) {
+	dir := t.TempDir()
+	cache := NewEmbeddingCache(dir)
+	oldKey := cache.key(ModelIdentity{Model: "m"}, "old")
+	newKey := cache.key(ModelIdentity{Model: "m"}, "new")
+	for _, key := range []string{oldKey, newKey} {
+		if err := cache.store(key, []float64{1}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFiles(t, dir, "stale.bin")
+	past := time.Now().Add(-48 * time.Hour)
+	for _, path := range []string{cache.path(oldKey), filepath.Join(dir, "stale.bin")} {
+		if err := os.Chtimes(path, past, past); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	removed, err := cache.Prune(24 * time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed != 1 {
+		t.Errorf("Prune removed %d entries, want 1", removed)
+	}
+	if _, ok := cache.load(oldKey); ok {
+		t.Error("old entry survived Prune")
+	}
+	if _, ok := cache.load(newKey); !ok {
+		t.Error("recent entry was pruned")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "stale.bin")); err != nil {
+		t.Errorf("Prune removed a file it did not create: %v", err)
+	}
+}
+
+func TestCacheStats(t *testing.T) {
+	baseline, err := NewBaselineEmbedder(EmbedderConfig{Backend: BackendHashing})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name         string
+		texts        []string
+		sent         []string
+		hits, misses int
+	}{
+		// A text repeated within a batch is embedded once and is not a hit.
+		{"repeated misses", []string{"a", "b", "a"}, []string{"a", "b"}, 0, 2},
+		{"repeated hits", []string{"a", "a", "b"}, nil, 3, 0},
+		{"hits and a repeated miss", []string{"c", "a", "c"}, []string{"c"}, 1, 1},
+	}
+	cache := NewEmbeddingCache(t.TempDir())
+	recorder := &batchRecorder{Embedder: baseline}
+	embedder := cache.Wrap(recorder)
+	var wantHits, wantMisses int
+	for _, tt := range tests {
+		recorder.batches = nil
+		embeddings, err := embedder.EmbedBatch(context.Background(), tt.texts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, _ := baseline.EmbedBatch(context.Background(), tt.texts)
+		if !reflect.DeepEqual(embeddings, want) {
+			t.Errorf("%s: embeddings differ from the uncached ones", tt.name)
+		}
+		var sent []string
+		if len(recorder.batches) > 0 {
+			sent = recorder.batches[0]
+		}
+		if len(recorder.batches) > 1 || !reflect.DeepEqual(sent, tt.sent) {
+			t.Errorf("%s: sent %q, want %q", tt.name, recorder.batches, tt.sent)
+		}
+		wantHits += tt.hits
+		wantMisses += tt.misses
+		if hits, misses := cache.Stats(); hits != wantHits || misses != wantMisses {
+			t.Errorf("%s: Stats = %d hits, %d misses, want %d and %d", tt.name, hits, misses, wantHits, wantMisses)
+		}
+	}
+}
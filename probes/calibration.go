@@ -0,0 +1,153 @@
+package probes
+
+import "fmt"
+
+// Split values for evidence items used in threshold calibration.
+const (
+	SplitCalibration = "calibration"
+	SplitTest        = "test"
+)
+
+// calibrateThreshold learns the threshold that maximises accuracy of
+// score > threshold on the given items. Among equally accurate cut-offs it
+// takes the lowest, as bestThreshold does, and places the threshold
+// midway between the highest score predicted irrelevant and the lowest
+// score predicted relevant so held-out scores are not judged against a
+// training score exactly.
+func calibrateThreshold(scores []float64, labels []bool) float64 {
+	t, _ := bestThreshold(scores, labels, confusion.accuracy)
+	next, ok := nextScoreAbove(scores, t)
+	if !ok || t < minScore(scores) {
+		return t
+	}
+	return (t + next) / 2
+}
+
+func nextScoreAbove(scores []float64, t float64) (float64, bool) {
+	next, ok := 0.0, false
+	for _, score := range scores {
+		if score > t && (!ok || score < next) {
+			next, ok = score, true
+		}
+	}
+	return next, ok
+}
+
+func minScore(scores []float64) float64 {
+	lo := scores[0]
+	for _, score := range scores[1:] {
+		if score < lo {
+			lo = score
+		}
+	}
+	return lo
+}
+
+// calibration is the result of learning a threshold on part of a dataset
+// and applying it to the rest.
+type calibration struct {
+	// Threshold is learned from the calibration split, or from every item
+	// when cross-validating.
+	Threshold float64
+	// HeldOutAccuracy is the accuracy on the test split, or the pooled
+	// accuracy over all cross-validation folds.
+	HeldOutAccuracy float64
+}
+
+// calibrateSplit learns a threshold on the items marked SplitCalibration and
+// reports its accuracy on the items marked SplitTest.
+func calibrateSplit(scores []float64, labels []bool, splits []string) calibration {
+	var trainScores, testScores []float64
+	var trainLabels, testLabels []bool
+	for i, split := range splits {
+		if split == SplitCalibration {
+			trainScores = append(trainScores, scores[i])
+			trainLabels = append(trainLabels, labels[i])
+		} else {
+			testScores = append(testScores, scores[i])
+			testLabels = append(testLabels, labels[i])
+		}
+	}
+	threshold := calibrateThreshold(trainScores, trainLabels)
+	return calibration{
+		Threshold:       threshold,
+		HeldOutAccuracy: confusionAt(testScores, testLabels, threshold).accuracy(),
+	}
+}
+
+// calibrateCrossValidated runs stratified k-fold cross-validation: relevant
+// items and then irrelevant items are dealt round-robin into folds in
+// dataset order, so every fold sees both classes when it can. folds <= 0 or
+// above the number of items gives leave-one-out.
+func calibrateCrossValidated(scores []float64, labels []bool, folds int) calibration {
+	n := len(scores)
+	if folds <= 0 || folds > n {
+		folds = n
+	}
+	fold := make([]int, n)
+	pos := 0
+	for _, class := range []bool{true, false} {
+		for i := range labels {
+			if labels[i] == class {
+				fold[i] = pos % folds
+				pos++
+			}
+		}
+	}
+
+	correct := 0
+	for k := 0; k < folds; k++ {
+		var trainScores []float64
+		var trainLabels []bool
+		for i := range scores {
+			if fold[i] != k {
+				trainScores = append(trainScores, scores[i])
+				trainLabels = append(trainLabels, labels[i])
+			}
+		}
+		if len(trainScores) == 0 {
+			continue
+		}
+		threshold := calibrateThreshold(trainScores, trainLabels)
+		for i := range scores {
+			if fold[i] == k && (scores[i] > threshold) == labels[i] {
+				correct++
+			}
+		}
+	}
+	return calibration{
+		Threshold:       calibrateThreshold(scores, labels),
+		HeldOutAccuracy: float64(correct) / float64(n),
+	}
+}
+
+func (c calibration) subMetrics() []SubMetric {
+	return []SubMetric{
+		{"Calibrated Threshold", c.Threshold},
+		{"Held-out Accuracy", c.HeldOutAccuracy},
+	}
+}
+
+// validateSplits checks that items either all carry a split or none do, and
+// that both splits are non-empty when used.
+func validateSplits(splits []string) (bool, error) {
+	counts := make(map[string]int)
+	for i, split := range splits {
+		switch split {
+		case "", SplitCalibration, SplitTest:
+		default:
+			return false, fmt.Errorf("item %d: unknown split %q (want %s or %s)", i+1, split, SplitCalibration, SplitTest)
+		}
+		counts[split]++
+	}
+	if counts[""] == len(splits) {
+		return false, nil
+	}
+	if counts[""] > 0 {
+		return false, fmt.Errorf("%d items have no split but others do", counts[""])
+	}
+	if counts[SplitCalibration] == 0 || counts[SplitTest] == 0 {
+		return false, fmt.Errorf("splits need both %s and %s items", SplitCalibration, SplitTest)
+	}
+	return true, nil
+}
@@ -0,0 +1,75 @@
+package probes
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCalibrateThreshold(t *testing.T) {
+	tests := []struct {
+		name   string
+		scores []float64
+		labels []bool
+		want   float64
+	}{
+		// Best cut-off 0.4 moves midway to the next score, 0.6.
+		{"midway", []float64{0.1, 0.4, 0.6, 0.9}, []bool{false, false, true, true}, 0.5},
+		{"unordered", []float64{0.9, 0.1, 0.6, 0.4}, []bool{true, false, true, false}, 0.5},
+		// Everything relevant: the threshold stays just below the lowest score.
+		{"all relevant", []float64{0.3, 0.7}, []bool{true, true}, math.Nextafter(0.3, math.Inf(-1))},
+		// Everything irrelevant: nothing scores above the highest score.
+		{"all irrelevant", []float64{0.3, 0.7}, []bool{false, false}, 0.7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := calibrateThreshold(tt.scores, tt.labels); math.Abs(got-tt.want) > 1e-12 {
+				t.Errorf("calibrateThreshold = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalibrateSplit(t *testing.T) {
+	scores := []float64{0.1, 0.6, 0.4, 0.9, 0.55, 0.45}
+	labels := []bool{false, true, false, true, true, true}
+	splits := []string{SplitCalibration, SplitCalibration, SplitCalibration, SplitCalibration, SplitTest, SplitTest}
+
+	// The calibration items give 0.5; 0.55 is then right and 0.45 wrong.
+	got := calibrateSplit(scores, labels, splits)
+	want := calibration{Threshold: 0.5, HeldOutAccuracy: 0.5}
+	if math.Abs(got.Threshold-want.Threshold) > 1e-12 || got.HeldOutAccuracy != want.HeldOutAccuracy {
+		t.Errorf("calibrateSplit = %+v, want %+v", got, want)
+	}
+}
+
+func TestCalibrateCrossValidated(t *testing.T) {
+	scores := []float64{0.1, 0.4, 0.6, 0.9}
+	labels := []bool{false, false, true, true}
+	tests := []struct {
+		name  string
+		folds int
+		want  calibration
+	}{
+		// Folds {0.1, 0.6} and {0.4, 0.9} learn 0.65 and 0.35, which
+		// misjudge 0.6 and 0.4.
+		{"two folds", 2, calibration{Threshold: 0.5, HeldOutAccuracy: 0.5}},
+		// Leaving out 0.6 gives 0.65 and leaving out 0.4 gives 0.35; the
+		// other two are judged correctly.
+		{"leave one out", 0, calibration{Threshold: 0.5, HeldOutAccuracy: 0.5}},
+		{"too many folds", 10, calibration{Threshold: 0.5, HeldOutAccuracy: 0.5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := calibrateCrossValidated(scores, labels, tt.folds)
+			if math.Abs(got.Threshold-tt.want.Threshold) > 1e-12 || got.HeldOutAccuracy != tt.want.HeldOutAccuracy {
+				t.Errorf("calibrateCrossValidated = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	// Well separated classes are judged correctly in every fold.
+	separated := calibrateCrossValidated([]float64{0.1, 0.2, 0.3, 0.7, 0.8, 0.9}, []bool{false, false, false, true, true, true}, 3)
+	if separated.HeldOutAccuracy != 1 || math.Abs(separated.Threshold-0.5) > 1e-12 {
+		t.Errorf("separated = %+v, want threshold 0.5 and accuracy 1", separated)
+	}
+}
@@ -0,0 +1,178 @@
+package probes
+
+import (
+	"math"
+	"sort"
+)
+
+// confusion counts binary predictions against labels.
+type confusion struct {
+	tp, fp, tn, fn int
+}
+
+func (c confusion) accuracy() float64 {
+	total := c.tp + c.fp + c.tn + c.fn
+	if total == 0 {
+		return 0
+	}
+	return float64(c.tp+c.tn) / float64(total)
+}
+
+func (c confusion) f1() float64 {
+	if c.tp == 0 {
+		return 0
+	}
+	return 2 * float64(c.tp) / float64(2*c.tp+c.fp+c.fn)
+}
+
+// confusionAt counts predictions of score > threshold against labels.
+func confusionAt(scores []float64, labels []bool, threshold float64) confusion {
+	var c confusion
+	for i, score := range scores {
+		predicted := score > threshold
+		switch {
+		case predicted && labels[i]:
+			c.tp++
+		case predicted:
+			c.fp++
+		case labels[i]:
+			c.fn++
+		default:
+			c.tn++
+		}
+	}
+	return c
+}
+
+// candidateThresholds returns every threshold that changes the predictions
+// of score > threshold: just below the lowest score, then each distinct
+// score in ascending order.
+func candidateThresholds(scores []float64) []float64 {
+	if len(scores) == 0 {
+		return nil
+	}
+	sorted := append([]float64(nil), scores...)
+	sort.Float64s(sorted)
+	thresholds := []float64{math.Nextafter(sorted[0], math.Inf(-1))}
+	for i, score := range sorted {
+		if i == 0 || score != sorted[i-1] {
+			thresholds = append(thresholds, score)
+		}
+	}
+	return thresholds
+}
+
+// bestThreshold returns the threshold that maximises objective over the
+// predictions score > threshold, and the objective's value there. Ties go
+// to the lowest threshold.
+func bestThreshold(scores []float64, labels []bool, objective func(confusion) float64) (float64, float64) {
+	bestT, bestV := 0.0, math.Inf(-1)
+	for _, t := range candidateThresholds(scores) {
+		if v := objective(confusionAt(scores, labels, t)); v > bestV {
+			bestT, bestV = t, v
+		}
+	}
+	if math.IsInf(bestV, -1) {
+		return 0, 0
+	}
+	return bestT, bestV
+}
+
+// rocAUC returns the probability that a random positive scores above a
+// random negative, counting ties as half. It returns 0 without both classes.
+func rocAUC(scores []float64, labels []bool) float64 {
+	var wins float64
+	var positives, negatives int
+	for i := range scores {
+		if !labels[i] {
+			negatives++
+			continue
+		}
+		positives++
+		for j := range scores {
+			if labels[j] {
+				continue
+			}
+			switch {
+			case scores[i] > scores[j]:
+				wins++
+			case scores[i] == scores[j]:
+				wins += 0.5
+			}
+		}
+	}
+	if positives == 0 || negatives == 0 {
+		return 0
+	}
+	return wins / float64(positives*negatives)
+}
+
+// averagePrecision summarises the precision-recall curve as the mean of
+// precision at each recall step, ranking by descending score. Tied scores
+// are taken as one step. It returns 0 without positives.
+func averagePrecision(scores []float64, labels []bool) float64 {
+	order := make([]int, len(scores))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return scores[order[a]] > scores[order[b]] })
+
+	positives := 0
+	for _, label := range labels {
+		if label {
+			positives++
+		}
+	}
+	if positives == 0 {
+		return 0
+	}
+	var ap float64
+	tp, seen := 0, 0
+	for i := 0; i < len(order); {
+		stepTP := 0
+		j := i
+		for ; j < len(order) && scores[order[j]] == scores[order[i]]; j++ {
+			if labels[order[j]] {
+				stepTP++
+			}
+		}
+		tp += stepTP
+		seen += j - i
+		ap += float64(stepTP) / float64(positives) * float64(tp) / float64(seen)
+		i = j
+	}
+	return ap
+}
+
+// classificationSubMetrics reports threshold-independent separability of
+// relevant and irrelevant items, and the best F1 and accuracy any single
+// threshold on Score achieves together with that threshold. ROC-AUC and
+// average precision are omitted unless both classes are present, as they
+// are undefined or trivially 1 otherwise.
+func classificationSubMetrics(items []ItemResult) []SubMetric {
+	scores := make([]float64, len(items))
+	labels := make([]bool, len(items))
+	positives := 0
+	for i, item := range items {
+		scores[i] = item.Score
+		labels[i] = item.Actual == labelRelevant
+		if labels[i] {
+			positives++
+		}
+	}
+	var subMetrics []SubMetric
+	if positives > 0 && positives < len(items) {
+		subMetrics = append(subMetrics,
+			SubMetric{"ROC-AUC", rocAUC(scores, labels)},
+			SubMetric{"Average Precision", averagePrecision(scores, labels)},
+		)
+	}
+	f1Threshold, f1 := bestThreshold(scores, labels, confusion.f1)
+	accThreshold, acc := bestThreshold(scores, labels, confusion.accuracy)
+	return append(subMetrics,
+		SubMetric{"Best F1", f1},
+		SubMetric{"Best F1 Threshold", f1Threshold},
+		SubMetric{"Best Accuracy", acc},
+		SubMetric{"Best Accuracy Threshold", accThreshold},
+	)
+}
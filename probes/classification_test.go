@@ -0,0 +1,109 @@
+package probes
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestRocAUC(t *testing.T) {
+	tests := []struct {
+		name   string
+		scores []float64
+		labels []bool
+		want   float64
+	}{
+		{"separated", []float64{0.9, 0.7, 0.3, 0.1}, []bool{true, true, false, false}, 1},
+		{"reversed", []float64{0.1, 0.3, 0.7, 0.9}, []bool{true, true, false, false}, 0},
+		{"interleaved", []float64{0.9, 0.8, 0.7, 0.6}, []bool{true, false, true, false}, 0.75},
+		{"tie", []float64{0.5, 0.5}, []bool{true, false}, 0.5},
+		{"only positives", []float64{0.9, 0.1}, []bool{true, true}, 0},
+		{"only negatives", []float64{0.9, 0.1}, []bool{false, false}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rocAUC(tt.scores, tt.labels); math.Abs(got-tt.want) > 1e-12 {
+				t.Errorf("rocAUC = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAveragePrecision(t *testing.T) {
+	tests := []struct {
+		name   string
+		scores []float64
+		labels []bool
+		want   float64
+	}{
+		{"separated", []float64{0.9, 0.7, 0.3, 0.1}, []bool{true, true, false, false}, 1},
+		// Precision 1/1 at the first positive and 2/3 at the second.
+		{"interleaved", []float64{0.9, 0.8, 0.7, 0.6}, []bool{true, false, true, false}, (1 + 2.0/3) / 2},
+		{"positive last", []float64{0.9, 0.1}, []bool{false, true}, 0.5},
+		{"tie is one step", []float64{0.5, 0.5}, []bool{true, false}, 0.5},
+		{"only negatives", []float64{0.9, 0.1}, []bool{false, false}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := averagePrecision(tt.scores, tt.labels); math.Abs(got-tt.want) > 1e-12 {
+				t.Errorf("averagePrecision = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBestThreshold(t *testing.T) {
+	tests := []struct {
+		name      string
+		scores    []float64
+		labels    []bool
+		objective func(confusion) float64
+		threshold float64
+		value     float64
+	}{
+		{"accuracy", []float64{0.1, 0.4, 0.6, 0.9}, []bool{false, false, true, true}, confusion.accuracy, 0.4, 1},
+		{"f1", []float64{0.1, 0.4, 0.6, 0.9}, []bool{false, false, true, true}, confusion.f1, 0.4, 1},
+		// Predicting everything relevant gives F1 2/3, beating any cut-off.
+		{"f1 keeps all", []float64{0.2, 0.8}, []bool{true, false}, confusion.f1, math.Nextafter(0.2, math.Inf(-1)), 2.0 / 3},
+		// All-relevant and all-irrelevant tie at 0.5; the lower threshold wins.
+		{"tie goes low", []float64{0.2, 0.8}, []bool{true, false}, confusion.accuracy, math.Nextafter(0.2, math.Inf(-1)), 0.5},
+		{"no scores", nil, nil, confusion.accuracy, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			threshold, value := bestThreshold(tt.scores, tt.labels, tt.objective)
+			if threshold != tt.threshold || math.Abs(value-tt.value) > 1e-12 {
+				t.Errorf("bestThreshold = (%v, %v), want (%v, %v)", threshold, value, tt.threshold, tt.value)
+			}
+		})
+	}
+}
+
+func TestClassificationSubMetrics(t *testing.T) {
+	item := func(score float64, relevant bool) ItemResult {
+		return ItemResult{Score: score, Actual: relevanceLabel(relevant)}
+	}
+	tests := []struct {
+		name  string
+		items []ItemResult
+		want  []string
+	}{
+		{"both classes", []ItemResult{item(0.9, true), item(0.1, false)},
+			[]string{"ROC-AUC", "Average Precision", "Best F1", "Best F1 Threshold", "Best Accuracy", "Best Accuracy Threshold"}},
+		{"only relevant", []ItemResult{item(0.9, true), item(0.1, true)},
+			[]string{"Best F1", "Best F1 Threshold", "Best Accuracy", "Best Accuracy Threshold"}},
+		{"only irrelevant", []ItemResult{item(0.9, false), item(0.1, false)},
+			[]string{"Best F1", "Best F1 Threshold", "Best Accuracy", "Best Accuracy Threshold"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var names []string
+			for _, sub := range classificationSubMetrics(tt.items) {
+				names = append(names, sub.Name)
+			}
+			if !reflect.DeepEqual(names, tt.want) {
+				t.Errorf("sub-metrics = %v, want %v", names, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package probes
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultDatasetsDir is the directory scanned for dataset files when none is
+// configured.
+const DefaultDatasetsDir = "datasets"
+
+// LoadEvidenceDatasets registers one evidence task per .json, .yaml or .yml
+// file directly inside dir, in file name order. A missing directory is not
+// an error.
+func LoadEvidenceDatasets(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("error reading datasets directory %s: %v", dir, err)
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		switch strings.ToLower(filepath.Ext(entry.Name())) {
+		case ".json", ".yaml", ".yml":
+			paths = append(paths, filepath.Join(dir, entry.Name()))
+		}
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		var dataset EvidenceDataset
+		if err := decodeDatasetFile(path, &dataset); err != nil {
+			return err
+		}
+		if dataset.Name == "" {
+			dataset.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		}
+		task, err := NewEvidenceTask(dataset)
+		if err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+		if err := registerUnique(task); err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+	}
+	return nil
+}
+
+// decodeDatasetFile decodes a JSON or YAML file into out, rejecting unknown
+// fields so typos in hand-written datasets are caught.
+func decodeDatasetFile(path string, out any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error reading dataset %s: %v", path, err)
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(out); err != nil {
+			return fmt.Errorf("error parsing dataset %s: %v", path, err)
+		}
+	default:
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(out); err != nil {
+			return fmt.Errorf("error parsing dataset %s: %v", path, err)
+		}
+	}
+	return nil
+}
+
+// registerUnique registers task unless a task with the same name exists.
+func registerUnique(task Task) error {
+	for _, existing := range TaskRegistry {
+		if existing.Name() == task.Name() {
+			return fmt.Errorf("a task named %q is already registered", task.Name())
+		}
+	}
+	RegisterTask(task)
+	return nil
+}
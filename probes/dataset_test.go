@@ -0,0 +1,123 @@
+package probes
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// withEmptyRegistry runs the test against an empty TaskRegistry and restores
+// the original afterwards.
+func withEmptyRegistry(t *testing.T) {
+	t.Helper()
+	saved := TaskRegistry
+	TaskRegistry = nil
+	t.Cleanup(func() { TaskRegistry = saved })
+}
+
+func TestEvidenceDatasetValidate(t *testing.T) {
+	grade := func(g int) *int { return &g }
+	tests := []struct {
+		name    string
+		dataset EvidenceDataset
+		err     string
+	}{
+		{"valid", EvidenceDataset{Name: "d", Query: "q", Evidence: []EvidenceItem{{Text: "a"}, {ID: "x", Text: "b"}}}, ""},
+		{"graded", EvidenceDataset{Name: "d", Query: "q", Evidence: []EvidenceItem{{Text: "a", Grade: grade(2)}, {Text: "b", Grade: grade(0)}}}, ""},
+		{"split", EvidenceDataset{Name: "d", Query: "q", Evidence: []EvidenceItem{{Text: "a", Split: SplitCalibration}, {Text: "b", Split: SplitTest}}}, ""},
+		{"no name", EvidenceDataset{Query: "q", Evidence: []EvidenceItem{{Text: "a"}}}, "dataset has no name"},
+		{"no query", EvidenceDataset{Name: "d", Evidence: []EvidenceItem{{Text: "a"}}}, `dataset "d" has no query`},
+		{"no evidence", EvidenceDataset{Name: "d", Query: "q"}, `dataset "d" has no evidence`},
+		{"empty text", EvidenceDataset{Name: "d", Query: "q", Evidence: []EvidenceItem{{Text: "a"}, {}}}, "evidence 2 has no text"},
+		{"partly graded", EvidenceDataset{Name: "d", Query: "q", Evidence: []EvidenceItem{{Text: "a"}, {Text: "b", Grade: grade(1)}}}, "evidence 1 has no grade but other items do"},
+		{"negative grade", EvidenceDataset{Name: "d", Query: "q", Evidence: []EvidenceItem{{Text: "a", Grade: grade(-1)}}}, "evidence 1 has negative grade -1"},
+		// The second item is numbered evidence-2, which the first already uses.
+		{"duplicate id", EvidenceDataset{Name: "d", Query: "q", Evidence: []EvidenceItem{{ID: "evidence-2", Text: "a"}, {Text: "b"}}}, `duplicate evidence id "evidence-2"`},
+		{"negative folds", EvidenceDataset{Name: "d", Query: "q", CalibrationFolds: -1, Evidence: []EvidenceItem{{Text: "a"}}}, "calibration_folds must not be negative"},
+		{"unknown split", EvidenceDataset{Name: "d", Query: "q", Evidence: []EvidenceItem{{Text: "a", Split: "train"}}}, `unknown split "train"`},
+		{"partly split", EvidenceDataset{Name: "d", Query: "q", Evidence: []EvidenceItem{{Text: "a", Split: SplitTest}, {Text: "b"}}}, "1 items have no split but others do"},
+		{"test split only", EvidenceDataset{Name: "d", Query: "q", Evidence: []EvidenceItem{{Text: "a", Split: SplitTest}}}, "splits need both"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.dataset.validate()
+			if tt.err == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				for i, item := range tt.dataset.Evidence {
+					if item.ID == "" {
+						t.Errorf("evidence %d has no id after validation", i+1)
+					}
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("err = %v, want it to contain %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestLoadEvidenceDatasets(t *testing.T) {
+	withEmptyRegistry(t)
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "b.json"), `{"name": "JSON Task", "query": "q", "evidence": [{"text": "a", "relevant": true}]}`)
+	writeFile(t, filepath.Join(dir, "a.yaml"), "query: q\nevidence:\n  - text: a\n    relevant: true\n")
+	writeFile(t, filepath.Join(dir, "notes.txt"), "not a dataset")
+	writeFile(t, filepath.Join(dir, "nested", "c.json"), "not read")
+
+	if err := LoadEvidenceDatasets(dir); err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, task := range TaskRegistry {
+		names = append(names, task.Name())
+	}
+	// Files load in name order; a.yaml is named after its file.
+	if want := []string{"a", "JSON Task"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("tasks = %v, want %v", names, want)
+	}
+
+	if err := LoadEvidenceDatasets(dir); err == nil || !strings.Contains(err.Error(), `a task named "a" is already registered`) {
+		t.Errorf("reload err = %v, want duplicate task", err)
+	}
+	if err := LoadEvidenceDatasets(filepath.Join(dir, "missing")); err != nil {
+		t.Errorf("missing directory: %v", err)
+	}
+}
+
+func TestLoadEvidenceDatasetsErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		err     string
+	}{
+		{"unknown json field", "d.json", `{"query": "q", "evidence": [{"text": "a", "relevent": true}]}`, `unknown field "relevent"`},
+		{"unknown yaml field", "d.yml", "query: q\nevidence:\n  - text: a\n    relevent: true\n", "field relevent not found"},
+		{"invalid json", "d.json", `{"query": `, "error parsing dataset"},
+		{"invalid dataset", "d.json", `{"query": "q"}`, `d.json: dataset "d" has no evidence`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withEmptyRegistry(t)
+			dir := t.TempDir()
+			writeFile(t, filepath.Join(dir, tt.file), tt.content)
+			if err := LoadEvidenceDatasets(dir); err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("err = %v, want it to contain %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestShippedEvidenceDatasets(t *testing.T) {
+	withEmptyRegistry(t)
+	if err := LoadEvidenceDatasets(filepath.Join("..", DefaultDatasetsDir)); err != nil {
+		t.Fatal(err)
+	}
+	if len(TaskRegistry) == 0 {
+		t.Error("no datasets loaded")
+	}
+}
@@ -0,0 +1,138 @@
+package probes
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Embedder turns text into embedding vectors for a single model served by a
+// single backend. Tasks receive embedders instead of talking to a server
+// directly, so the same probe can be pointed at any backend or at a fake.
+type Embedder interface {
+	// Name returns the label used for the embedder in task results.
+	Name() string
+	// Model returns the model identifier sent to the backend.
+	Model() string
+	// Dimension returns the embedding size, or 0 if no embedding has been
+	// produced yet.
+	Dimension() int
+	// Identity describes the exact model behind the embedder. Backends that
+	// can report a model digest look it up on first use.
+	Identity(ctx context.Context) (ModelIdentity, error)
+	Embed(ctx context.Context, text string) ([]float64, error)
+	EmbedBatch(ctx context.Context, texts []string) ([][]float64, error)
+}
+
+// ModelIdentity pins down which model produced an embedding. Two embedders
+// with equal identities produce the same vector for the same text.
+type ModelIdentity struct {
+	Backend string `json:"backend"`
+	BaseURL string `json:"base_url,omitempty"`
+	Model   string `json:"model"`
+	// Digest is the backend's content hash of the model weights, empty when
+	// the backend cannot report one.
+	Digest string `json:"digest,omitempty"`
+	Prefix string `json:"prefix,omitempty"`
+}
+
+// Supported values for EmbedderConfig.Backend.
+const (
+	BackendOllama = "ollama"
+	BackendOpenAI = "openai"
+	// BackendRandom and BackendHashing are local baselines; see
+	// NewBaselineEmbedder.
+	BackendRandom  = "random"
+	BackendHashing = "hashing"
+)
+
+// EmbedderConfig describes how to reach a model. Zero values select the
+// backend defaults.
+type EmbedderConfig struct {
+	Backend string
+	BaseURL string
+	Model   string
+	// Alias replaces the model name as the embedder's display name.
+	Alias string
+
+	// APIKey is sent as a bearer token when set.
+	APIKey string
+	// Headers are added to every request, e.g. for a proxy.
+	Headers map[string]string
+	// Timeout bounds each HTTP attempt; zero selects DefaultRequestTimeout.
+	Timeout time.Duration
+	// MaxRetries bounds the retries of transient failures; zero selects
+	// DefaultMaxRetries and a negative value disables retries.
+	MaxRetries int
+	// KeepAlive controls how long Ollama keeps the model loaded, e.g. "10m".
+	KeepAlive string
+	// MaxBatchSize caps the texts per Ollama or OpenAI embedding request;
+	// zero selects DefaultMaxBatchSize.
+	MaxBatchSize int
+
+	// Dimensions asks backends that support it to truncate embeddings, and
+	// sets the vector size of the baselines.
+	Dimensions int
+	// EncodingFormat is "float" or "base64" for OpenAI-compatible servers.
+	EncodingFormat string
+
+	// Prefix is prepended to every text before embedding, for models that
+	// expect instructions such as "search_query: ".
+	Prefix string
+}
+
+// NewEmbedder builds the Embedder selected by cfg.Backend.
+func NewEmbedder(cfg EmbedderConfig) (Embedder, error) {
+	if cfg.Model == "" && cfg.Backend != BackendRandom && cfg.Backend != BackendHashing {
+		return nil, fmt.Errorf("model name is required")
+	}
+	switch cfg.Backend {
+	case BackendRandom, BackendHashing:
+		return NewBaselineEmbedder(cfg)
+	case "", BackendOllama:
+		return NewOllamaEmbedder(cfg), nil
+	case BackendOpenAI:
+		return NewOpenAIEmbedder(cfg)
+	default:
+		return nil, fmt.Errorf("unknown backend %q for model %s", cfg.Backend, cfg.Model)
+	}
+}
+
+// DisplayName returns the name the embedder built from cfg reports: the
+// alias if set, else the model, else the baseline's default model name.
+func (cfg EmbedderConfig) DisplayName() string {
+	if cfg.Alias != "" {
+		return cfg.Alias
+	}
+	if cfg.Model == "" && (cfg.Backend == BackendRandom || cfg.Backend == BackendHashing) {
+		return cfg.Backend + "-baseline"
+	}
+	return cfg.Model
+}
+
+func withPrefix(prefix string, texts []string) []string {
+	if prefix == "" {
+		return texts
+	}
+	prefixed := make([]string, len(texts))
+	for i, text := range texts {
+		prefixed[i] = prefix + text
+	}
+	return prefixed
+}
+
+// TokensUsed returns the tokens reported by the backend behind e, looking
+// through wrappers such as the embedding cache. Backends that do not report
+// usage return 0.
+func TokensUsed(e Embedder) int {
+	for {
+		if usage, ok := e.(interface{ TokensUsed() int }); ok {
+			return usage.TokensUsed()
+		}
+		wrapper, ok := e.(interface{ Unwrap() Embedder })
+		if !ok {
+			return 0
+		}
+		e = wrapper.Unwrap()
+	}
+}
@@ -0,0 +1,55 @@
+package probes
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Sentinel errors for embedding failures. Backends wrap them, so callers
+// test with errors.Is.
+var (
+	// ErrModelNotFound means the server does not have the requested model.
+	ErrModelNotFound = errors.New("model not found")
+	// ErrServerOverloaded means the server is temporarily unable to answer;
+	// the request may succeed if retried.
+	ErrServerOverloaded = errors.New("server overloaded")
+	// ErrBadResponse means the server answered with an error or with a body
+	// that could not be understood.
+	ErrBadResponse = errors.New("bad response")
+	// ErrEmptyEmbedding means the server answered without a vector.
+	ErrEmptyEmbedding = errors.New("empty embedding")
+)
+
+// APIError is an error reported by an embedding server, either through an
+// HTTP error status or an {"error": ...} body. StatusCode is 0 when the error
+// body came with a success status.
+type APIError struct {
+	StatusCode int
+	Message    string
+	kind       error
+}
+
+func newAPIError(statusCode int, message string) *APIError {
+	e := &APIError{StatusCode: statusCode, Message: message, kind: ErrBadResponse}
+	lower := strings.ToLower(message)
+	switch {
+	case strings.Contains(lower, "model") && (strings.Contains(lower, "not found") || strings.Contains(lower, "does not exist")):
+		e.kind = ErrModelNotFound
+	case statusCode == http.StatusTooManyRequests, statusCode >= 500:
+		e.kind = ErrServerOverloaded
+	}
+	return e
+}
+
+func (e *APIError) Error() string {
+	if e.StatusCode == 0 {
+		return fmt.Sprintf("%v: %s", e.kind, e.Message)
+	}
+	return fmt.Sprintf("%v: server returned %d: %s", e.kind, e.StatusCode, e.Message)
+}
+
+func (e *APIError) Unwrap() error {
+	return e.kind
+}
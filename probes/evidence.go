@@ -0,0 +1,232 @@
+package probes
+
+import (
+	"context"
+	"fmt"
+)
+
+// DefaultRelevanceThreshold is the similarity above which evidence is
+// predicted relevant when a dataset does not set its own threshold.
+const DefaultRelevanceThreshold = 0.5
+
+// EvidenceDataset is a query plus evidence texts labelled for relevance,
+// loaded from a file in the datasets directory.
+type EvidenceDataset struct {
+	Name  string `json:"name" yaml:"name"`
+	Query string `json:"query" yaml:"query"`
+	// Threshold overrides DefaultRelevanceThreshold.
+	Threshold *float64 `json:"threshold,omitempty" yaml:"threshold,omitempty"`
+	// CalibrationFolds sets k for cross-validated threshold calibration
+	// when items have no split. Zero means leave-one-out.
+	CalibrationFolds int            `json:"calibration_folds,omitempty" yaml:"calibration_folds,omitempty"`
+	Evidence         []EvidenceItem `json:"evidence" yaml:"evidence"`
+}
+
+// EvidenceItem is one evidence text and its ground-truth relevance.
+type EvidenceItem struct {
+	ID       string `json:"id,omitempty" yaml:"id,omitempty"`
+	Text     string `json:"text" yaml:"text"`
+	Lang     string `json:"lang,omitempty" yaml:"lang,omitempty"`
+	Relevant bool   `json:"relevant" yaml:"relevant"`
+	// Grade is an optional ordinal relevance grade, higher meaning more
+	// relevant (e.g. 2, 1, 0 for rubric classes A, B, C). When any item is
+	// graded, all must be, and the task also reports ranking metrics.
+	Grade *int `json:"grade,omitempty" yaml:"grade,omitempty"`
+	// Split is SplitCalibration or SplitTest. When items are split, each
+	// model's threshold is learned on the calibration items and scored on
+	// the test items; otherwise it is cross-validated.
+	Split string `json:"split,omitempty" yaml:"split,omitempty"`
+	// Note is free text for dataset authors, e.g. the rubric class.
+	Note string `json:"note,omitempty" yaml:"note,omitempty"`
+}
+
+func (d *EvidenceDataset) validate() error {
+	if d.Name == "" {
+		return fmt.Errorf("dataset has no name")
+	}
+	if d.Query == "" {
+		return fmt.Errorf("dataset %q has no query", d.Name)
+	}
+	if len(d.Evidence) == 0 {
+		return fmt.Errorf("dataset %q has no evidence", d.Name)
+	}
+	seen := make(map[string]bool)
+	graded := d.graded()
+	for i := range d.Evidence {
+		item := &d.Evidence[i]
+		if item.Text == "" {
+			return fmt.Errorf("dataset %q: evidence %d has no text", d.Name, i+1)
+		}
+		if graded && item.Grade == nil {
+			return fmt.Errorf("dataset %q: evidence %d has no grade but other items do", d.Name, i+1)
+		}
+		if item.Grade != nil && *item.Grade < 0 {
+			return fmt.Errorf("dataset %q: evidence %d has negative grade %d", d.Name, i+1, *item.Grade)
+		}
+		if item.ID == "" {
+			item.ID = fmt.Sprintf("evidence-%d", i+1)
+		}
+		if seen[item.ID] {
+			return fmt.Errorf("dataset %q: duplicate evidence id %q", d.Name, item.ID)
+		}
+		seen[item.ID] = true
+	}
+	if d.CalibrationFolds < 0 {
+		return fmt.Errorf("dataset %q: calibration_folds must not be negative", d.Name)
+	}
+	if _, err := validateSplits(d.splits()); err != nil {
+		return fmt.Errorf("dataset %q: %v", d.Name, err)
+	}
+	return nil
+}
+
+func (d *EvidenceDataset) splits() []string {
+	splits := make([]string, len(d.Evidence))
+	for i, item := range d.Evidence {
+		splits[i] = item.Split
+	}
+	return splits
+}
+
+// calibrate learns a threshold for one model's item scores, from the
+// calibration split when the dataset has one and by cross-validation
+// otherwise.
+func (d *EvidenceDataset) calibrate(items []ItemResult) calibration {
+	scores := make([]float64, len(items))
+	labels := make([]bool, len(items))
+	for i, item := range items {
+		scores[i] = item.Score
+		labels[i] = item.Actual == labelRelevant
+	}
+	splits := d.splits()
+	if split, _ := validateSplits(splits); split {
+		return calibrateSplit(scores, labels, splits)
+	}
+	return calibrateCrossValidated(scores, labels, d.CalibrationFolds)
+}
+
+// graded reports whether any evidence item carries a grade.
+func (d *EvidenceDataset) graded() bool {
+	for _, item := range d.Evidence {
+		if item.Grade != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *EvidenceDataset) threshold() float64 {
+	if d.Threshold != nil {
+		return *d.Threshold
+	}
+	return DefaultRelevanceThreshold
+}
+
+// evidenceTask scores each model by how accurately thresholded query/evidence
+// similarity predicts the dataset's relevance labels.
+type evidenceTask struct {
+	dataset EvidenceDataset
+}
+
+// NewEvidenceTask returns a task for the given dataset.
+func NewEvidenceTask(dataset EvidenceDataset) (Task, error) {
+	if err := dataset.validate(); err != nil {
+		return nil, err
+	}
+	return &evidenceTask{dataset: dataset}, nil
+}
+
+func (t *evidenceTask) Name() string {
+	return t.dataset.Name
+}
+
+func (t *evidenceTask) Category() string {
+	return "Evidence"
+}
+
+func (t *evidenceTask) Metric() MetricDescriptor {
+	return accuracyMetric
+}
+
+func (t *evidenceTask) ItemScoresAreSimilarities() bool {
+	return true
+}
+
+// MetricFromItems returns the share of items whose predicted label matches
+// the actual one.
+func (t *evidenceTask) MetricFromItems(items []ItemResult) float64 {
+	correct := 0
+	for _, item := range items {
+		if item.Predicted == item.Actual {
+			correct++
+		}
+	}
+	return ratio(correct, len(items))
+}
+
+func (t *evidenceTask) Run(ctx context.Context, embedders []Embedder) (map[string]TaskResult, error) {
+	query := t.dataset.Query
+	evidenceItems := t.dataset.Evidence
+	threshold := t.dataset.threshold()
+
+	results := make(map[string]TaskResult)
+
+	for _, embedder := range embedders {
+		model := embedder.Name()
+		fmt.Printf("Model: %s\n", model)
+		correct := 0
+		texts := []string{query}
+		for _, evidence := range evidenceItems {
+			texts = append(texts, evidence.Text)
+		}
+		embeddings, err := embedder.EmbedBatch(ctx, texts)
+		if err != nil {
+			return nil, fmt.Errorf("error getting embeddings: %v", err)
+		}
+		queryEmb := embeddings[0]
+
+		var items []ItemResult
+		for i, evidence := range evidenceItems {
+			sim, err := cosineSimilarity(queryEmb, embeddings[i+1])
+			if err != nil {
+				return nil, fmt.Errorf("error computing similarity for evidence %s: %v", evidence.ID, err)
+			}
+
+			predictedRelevant := sim > threshold
+			label := evidence.ID
+			if evidence.Lang != "" {
+				label = fmt.Sprintf("%s (%s)", evidence.ID, evidence.Lang)
+			}
+			fmt.Printf("Evidence %s: Similarity=%.4f, Predicted=%v, Actual=%v\n", label, sim, predictedRelevant, evidence.Relevant)
+			if predictedRelevant == evidence.Relevant {
+				correct++
+			}
+			item := ItemResult{
+				ID:        evidence.ID,
+				InputIDs:  []string{"query", evidence.ID},
+				Texts:     []string{query, evidence.Text},
+				Score:     sim,
+				Predicted: relevanceLabel(predictedRelevant),
+				Actual:    relevanceLabel(evidence.Relevant),
+			}
+			if evidence.Grade != nil {
+				grade := float64(*evidence.Grade)
+				item.Gold = &grade
+			}
+			items = append(items, item)
+		}
+
+		accuracy := float64(correct) / float64(len(evidenceItems))
+		fmt.Printf("Accuracy: %.4f (%d/%d correct)\n", accuracy, correct, len(evidenceItems))
+		calibrated := t.dataset.calibrate(items)
+		fmt.Printf("Calibrated threshold: %.4f, held-out accuracy: %.4f\n", calibrated.Threshold, calibrated.HeldOutAccuracy)
+		subMetrics := append(relevanceSubMetrics(items), classificationSubMetrics(items)...)
+		subMetrics = append(subMetrics, calibrated.subMetrics()...)
+		if t.dataset.graded() {
+			subMetrics = append(subMetrics, gradedSubMetrics(items)...)
+		}
+		results[model] = TaskResult{Metric: accuracy, SubMetrics: subMetrics, Items: items}
+	}
+
+	return results, nil
+}
@@ -0,0 +1,124 @@
+package probes
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Export formats accepted by ExportRun.
+const (
+	// FormatJSON is the whole RunRecord, as read back by LoadRun.
+	FormatJSON = "json"
+	// FormatCSV has one row per task, model and metric, primary metric
+	// first.
+	FormatCSV = "csv"
+	// FormatJSONL has one line per scored item.
+	FormatJSONL = "jsonl"
+	// FormatMarkdown is a GitHub-flavoured Markdown report.
+	FormatMarkdown = "markdown"
+	// FormatHTML is a self-contained HTML report with charts.
+	FormatHTML = "html"
+)
+
+// ExportFormats lists the formats accepted by ExportRun.
+var ExportFormats = []string{FormatJSON, FormatCSV, FormatJSONL, FormatMarkdown, FormatHTML}
+
+// ExportRun writes record to w in the given format. Cancelled tasks appear
+// only in FormatJSON.
+func ExportRun(w io.Writer, record RunRecord, format string) error {
+	switch format {
+	case FormatJSON:
+		data, err := json.MarshalIndent(record, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error encoding run: %v", err)
+		}
+		_, err = w.Write(append(data, '\n'))
+		return err
+	case FormatCSV:
+		return exportCSV(w, record)
+	case FormatJSONL:
+		return exportJSONL(w, record)
+	case FormatMarkdown:
+		return exportMarkdown(w, record)
+	case FormatHTML:
+		return exportHTML(w, record)
+	default:
+		return fmt.Errorf("unknown export format %q (want %s)", format, strings.Join(ExportFormats, ", "))
+	}
+}
+
+var csvHeader = []string{
+	"task_number", "task_name", "model", "baseline", "metric", "primary", "value",
+	"ci_low", "ci_high", "winner", "audit_failures", "duration_ms", "embed_calls", "embedded_texts",
+}
+
+func exportCSV(w io.Writer, record RunRecord) error {
+	out := csv.NewWriter(w)
+	out.Write(csvHeader)
+	for _, task := range record.Tasks {
+		if task.Cancelled {
+			continue
+		}
+		winners := make(map[string]bool)
+		for _, name := range task.Outcome.Winners {
+			winners[name] = true
+		}
+		for _, model := range record.Models {
+			result, ok := task.Results[model]
+			if !ok {
+				continue
+			}
+			row := func(metric string, primary bool, value float64, ci *ConfidenceInterval) []string {
+				var low, high string
+				if ci != nil {
+					low, high = formatFloat(ci.Low), formatFloat(ci.High)
+				}
+				return []string{
+					strconv.Itoa(task.Number), task.Name, model,
+					strconv.FormatBool(record.IsBaseline(model)),
+					metric, strconv.FormatBool(primary), formatFloat(value), low, high,
+					strconv.FormatBool(winners[model]),
+					strings.Join(result.AuditFailures, "; "),
+					formatFloat(float64(result.Duration.Microseconds()) / 1000),
+					strconv.Itoa(result.EmbedCalls), strconv.Itoa(result.EmbeddedTexts),
+				}
+			}
+			out.Write(row(task.Metric.Name, true, result.Metric, result.CI))
+			for _, sub := range result.SubMetrics {
+				out.Write(row(sub.Name, false, sub.Value, nil))
+			}
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// itemLine is one line of the FormatJSONL export.
+type itemLine struct {
+	TaskNumber int    `json:"task_number"`
+	TaskName   string `json:"task_name"`
+	Model      string `json:"model"`
+	ItemResult
+}
+
+func exportJSONL(w io.Writer, record RunRecord) error {
+	encoder := json.NewEncoder(w)
+	for _, task := range record.Tasks {
+		for _, model := range record.Models {
+			for _, item := range task.Results[model].Items {
+				if err := encoder.Encode(itemLine{task.Number, task.Name, model, item}); err != nil {
+					return fmt.Errorf("error encoding item %s: %v", item.ID, err)
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package probes
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// exportRecord is a run with a scored task, a task missing one model's
+// result and a cancelled task.
+func exportRecord() RunRecord {
+	gold := 2.0
+	return RunRecord{
+		Models:    []string{"a", "hashing-baseline"},
+		Baselines: []string{"hashing-baseline"},
+		Tasks: []TaskRecord{
+			{
+				Number: 1, Name: "Evidence", Metric: accuracyMetric,
+				Results: map[string]TaskResult{
+					"a": {
+						Metric: 0.75, CI: &ConfidenceInterval{Low: 0.5, High: 1},
+						SubMetrics: []SubMetric{{"nDCG", 0.9}, {"Kendall Tau", -0.5}},
+						Items: []ItemResult{
+							{ID: "e1", InputIDs: []string{"query", "e1"}, Texts: []string{"q", "first"}, Score: 0.8, Predicted: "relevant", Actual: "relevant", Gold: &gold},
+							{ID: "e2", Texts: []string{"q", "second"}, Score: 0.1},
+						},
+						Duration: 1500 * time.Microsecond, EmbedCalls: 2, EmbeddedTexts: 3,
+					},
+					"hashing-baseline": {
+						Metric:        0.25,
+						AuditFailures: []string{"all 2 items have similarity 0.5000", "e2: input 2 was never embedded"},
+						Items:         []ItemResult{{ID: "e1", Score: 0.5}},
+					},
+				},
+				Outcome: Outcome{Winners: []string{"a"}},
+			},
+			{
+				Number: 3, Name: "Pairs, quoted", Metric: MetricDescriptor{Name: "Mean Similarity"},
+				Results: map[string]TaskResult{"hashing-baseline": {Metric: 0.5}},
+			},
+			{Number: 4, Name: "Stopped", Metric: accuracyMetric, Cancelled: true},
+		},
+	}
+}
+
+func TestExportCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := ExportRun(&buf, exportRecord(), FormatCSV); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"task_number", "task_name", "model", "baseline", "metric", "primary", "value",
+			"ci_low", "ci_high", "winner", "audit_failures", "duration_ms", "embed_calls", "embedded_texts"},
+		{"1", "Evidence", "a", "false", "Accuracy", "true", "0.75", "0.5", "1", "true", "", "1.5", "2", "3"},
+		{"1", "Evidence", "a", "false", "nDCG", "false", "0.9", "", "", "true", "", "1.5", "2", "3"},
+		{"1", "Evidence", "a", "false", "Kendall Tau", "false", "-0.5", "", "", "true", "", "1.5", "2", "3"},
+		{"1", "Evidence", "hashing-baseline", "true", "Accuracy", "true", "0.25", "", "", "false",
+			"all 2 items have similarity 0.5000; e2: input 2 was never embedded", "0", "0", "0"},
+		{"3", "Pairs, quoted", "hashing-baseline", "true", "Mean Similarity", "true", "0.5", "", "", "false", "", "0", "0", "0"},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("rows =\n%q\nwant\n%q", rows, want)
+	}
+}
+
+func TestExportJSONL(t *testing.T) {
+	var buf bytes.Buffer
+	if err := ExportRun(&buf, exportRecord(), FormatJSONL); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		`{"task_number":1,"task_name":"Evidence","model":"a","id":"e1","input_ids":["query","e1"],"texts":["q","first"],"score":0.8,"predicted":"relevant","actual":"relevant","gold":2}`,
+		`{"task_number":1,"task_name":"Evidence","model":"a","id":"e2","texts":["q","second"],"score":0.1}`,
+		`{"task_number":1,"task_name":"Evidence","model":"hashing-baseline","id":"e1","score":0.5}`,
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("lines =\n%s\nwant\n%s", strings.Join(lines, "\n"), strings.Join(want, "\n"))
+	}
+	for _, line := range lines {
+		var item itemLine
+		if err := json.Unmarshal([]byte(line), &item); err != nil {
+			t.Errorf("line %s does not decode: %v", line, err)
+		}
+	}
+}
+
+func TestExportJSONRoundTrip(t *testing.T) {
+	record := exportRecord()
+	var buf bytes.Buffer
+	if err := ExportRun(&buf, record, FormatJSON); err != nil {
+		t.Fatal(err)
+	}
+	var decoded RunRecord
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded.Tasks) != 3 || !decoded.Tasks[2].Cancelled {
+		t.Errorf("tasks = %+v, want all three with the last cancelled", decoded.Tasks)
+	}
+	if got := decoded.Tasks[0].Results["a"]; got.Metric != 0.75 || len(got.Items) != 2 || *got.Items[0].Gold != 2 {
+		t.Errorf("result = %+v, want the exported one", got)
+	}
+}
+
+func TestExportUnknownFormat(t *testing.T) {
+	err := ExportRun(&bytes.Buffer{}, RunRecord{}, "xml")
+	if err == nil || !strings.Contains(err.Error(), `unknown export format "xml"`) {
+		t.Errorf("err = %v, want unknown format", err)
+	}
+}
@@ -0,0 +1,164 @@
+package probes
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DefaultHistoryDir is where runs are kept when no directory is configured.
+const DefaultHistoryDir = ".probe-history"
+
+// historyTimeFormat names history files so they sort by start time.
+const historyTimeFormat = "20060102T150405.000000000Z"
+
+// History keeps every run as a JSON file in a directory, named by the run's
+// start time, so later runs can be compared with earlier ones.
+type History struct {
+	dir string
+}
+
+// NewHistory returns the history kept in dir. The directory is created on
+// first save.
+func NewHistory(dir string) *History {
+	return &History{dir: dir}
+}
+
+// Dir returns the history's directory.
+func (h *History) Dir() string {
+	return h.dir
+}
+
+// Save adds record to the history and returns the file it was written to.
+func (h *History) Save(record RunRecord) (string, error) {
+	if err := os.MkdirAll(h.dir, 0o755); err != nil {
+		return "", fmt.Errorf("error creating history directory: %v", err)
+	}
+	path := filepath.Join(h.dir, record.Started.UTC().Format(historyTimeFormat)+".json")
+	if err := SaveRun(path, record, FormatJSON); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// Runs returns the paths of the saved runs, oldest first. A missing
+// directory holds no runs.
+func (h *History) Runs() ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(h.dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("error listing history: %v", err)
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// Latest returns the path of the newest saved run.
+func (h *History) Latest() (string, error) {
+	paths, err := h.Runs()
+	if err != nil {
+		return "", err
+	}
+	if len(paths) == 0 {
+		return "", fmt.Errorf("no runs in history %s", h.dir)
+	}
+	return paths[len(paths)-1], nil
+}
+
+// ConfigHash returns a short content hash of a run's saved config, so runs
+// made with the same config can be recognised.
+func ConfigHash(config []byte) string {
+	sum := sha256.Sum256(config)
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// Regression is a score that got worse between a baseline run and a later
+// one by more than the allowed tolerance, or a result that started failing
+// the audit.
+type Regression struct {
+	TaskName string
+	Model    string
+	Metric   string
+	Baseline float64
+	Current  float64
+	// Audit is set when the current result failed the audit and the
+	// baseline's did not.
+	Audit bool
+}
+
+func (r Regression) String() string {
+	if r.Audit {
+		return fmt.Sprintf("%s: %s: now fails the audit", r.TaskName, r.Model)
+	}
+	return fmt.Sprintf("%s: %s: %s %.4f -> %.4f (%+.4f)", r.TaskName, r.Model, r.Metric, r.Baseline, r.Current, r.Current-r.Baseline)
+}
+
+// FindRegressions compares current with baseline task by task and model by
+// model. A score regresses when it is worse in the metric's direction by
+// more than both tolerance and the metric's tie tolerance. Tasks and models
+// missing from either run, cancelled tasks and control tasks are skipped.
+func FindRegressions(baseline, current RunRecord, tolerance float64) []Regression {
+	var regressions []Regression
+	for _, task := range current.Tasks {
+		old, ok := baseline.Task(task.Name)
+		if !ok || old.Cancelled || task.Cancelled || task.Metric.Control {
+			continue
+		}
+		for _, model := range current.Models {
+			before, okBefore := old.Results[model]
+			after, okAfter := task.Results[model]
+			if !okBefore || !okAfter {
+				continue
+			}
+			if len(after.AuditFailures) > 0 && len(before.AuditFailures) == 0 {
+				regressions = append(regressions, Regression{TaskName: task.Name, Model: model, Metric: task.Metric.Name,
+					Baseline: before.Metric, Current: after.Metric, Audit: true})
+				continue
+			}
+			if task.Metric.Better(before.Metric, after.Metric) && math.Abs(before.Metric-after.Metric) > tolerance {
+				regressions = append(regressions, Regression{TaskName: task.Name, Model: model, Metric: task.Metric.Name,
+					Baseline: before.Metric, Current: after.Metric})
+			}
+		}
+	}
+	return regressions
+}
+
+// IdentityChanges describes each model whose identity differs between two
+// runs, such as an Ollama tag that now points at a new digest.
+func IdentityChanges(baseline, current RunRecord) []string {
+	var changes []string
+	for _, model := range current.Models {
+		before, okBefore := baseline.Identities[model]
+		after, okAfter := current.Identities[model]
+		if !okBefore || !okAfter || before == after {
+			continue
+		}
+		var diffs []string
+		if before.Digest != after.Digest {
+			diffs = append(diffs, fmt.Sprintf("digest %s -> %s", orNone(before.Digest), orNone(after.Digest)))
+		}
+		if before.Model != after.Model {
+			diffs = append(diffs, fmt.Sprintf("model %s -> %s", before.Model, after.Model))
+		}
+		if before.Backend != after.Backend || before.BaseURL != after.BaseURL {
+			diffs = append(diffs, fmt.Sprintf("server %s %s -> %s %s", before.Backend, before.BaseURL, after.Backend, after.BaseURL))
+		}
+		if before.Prefix != after.Prefix {
+			diffs = append(diffs, fmt.Sprintf("prefix %q -> %q", before.Prefix, after.Prefix))
+		}
+		changes = append(changes, fmt.Sprintf("%s: %s", model, strings.Join(diffs, ", ")))
+	}
+	return changes
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "(none)"
+	}
+	return s
+}
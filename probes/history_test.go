@@ -0,0 +1,133 @@
+package probes
+
+import (
+	"math"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// oneTaskRun is a run of a single task with the given per-model results.
+func oneTaskRun(metric MetricDescriptor, results map[string]TaskResult) RunRecord {
+	models := make([]string, 0, len(results))
+	for _, model := range []string{"a", "b"} {
+		if _, ok := results[model]; ok {
+			models = append(models, model)
+		}
+	}
+	return RunRecord{Models: models, Tasks: []TaskRecord{{Number: 1, Name: "task", Metric: metric, Results: results}}}
+}
+
+func TestFindRegressions(t *testing.T) {
+	higher := MetricDescriptor{Name: "Accuracy", Direction: HigherIsBetter, Min: 0, Max: 1}
+	lower := MetricDescriptor{Name: "Error", Direction: LowerIsBetter, Min: 0, Max: math.Inf(1)}
+	tied := higher
+	tied.Tolerance = 0.05
+	control := higher
+	control.Control = true
+	score := func(v float64) map[string]TaskResult { return map[string]TaskResult{"a": {Metric: v}} }
+	tests := []struct {
+		name      string
+		metric    MetricDescriptor
+		before    map[string]TaskResult
+		after     map[string]TaskResult
+		tolerance float64
+		want      []Regression
+	}{
+		{"drop beyond tolerance", higher, score(0.8), score(0.7), 0.01, []Regression{{TaskName: "task", Model: "a", Metric: "Accuracy", Baseline: 0.8, Current: 0.7}}},
+		{"drop within tolerance", higher, score(0.8), score(0.795), 0.01, nil},
+		{"improvement", higher, score(0.7), score(0.8), 0.01, nil},
+		{"zero tolerance", higher, score(0.8), score(0.79), 0, []Regression{{TaskName: "task", Model: "a", Metric: "Accuracy", Baseline: 0.8, Current: 0.79}}},
+		{"lower is better rise", lower, score(1), score(1.5), 0.01, []Regression{{TaskName: "task", Model: "a", Metric: "Error", Baseline: 1, Current: 1.5}}},
+		{"lower is better fall", lower, score(1.5), score(1), 0.01, nil},
+		{"within the metric's tie tolerance", tied, score(0.8), score(0.76), 0.01, nil},
+		{"beyond the metric's tie tolerance", tied, score(0.8), score(0.7), 0.01, []Regression{{TaskName: "task", Model: "a", Metric: "Accuracy", Baseline: 0.8, Current: 0.7}}},
+		{"control task", control, score(0.8), score(0.1), 0.01, nil},
+		{"new audit failure", higher, score(0.8), map[string]TaskResult{"a": {Metric: 0.9, AuditFailures: []string{"constant"}}}, 0.01,
+			[]Regression{{TaskName: "task", Model: "a", Metric: "Accuracy", Baseline: 0.8, Current: 0.9, Audit: true}}},
+		{"audit failure in both", higher, map[string]TaskResult{"a": {Metric: 0.8, AuditFailures: []string{"constant"}}},
+			map[string]TaskResult{"a": {Metric: 0.8, AuditFailures: []string{"constant"}}}, 0.01, nil},
+		{"model only in baseline", higher, map[string]TaskResult{"a": {Metric: 0.8}, "b": {Metric: 0.9}}, score(0.8), 0.01, nil},
+		{"model only in current", higher, score(0.8), map[string]TaskResult{"a": {Metric: 0.8}, "b": {Metric: 0}}, 0.01, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FindRegressions(oneTaskRun(tt.metric, tt.before), oneTaskRun(tt.metric, tt.after), tt.tolerance)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("regressions = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindRegressionsSkipsTasks(t *testing.T) {
+	before := oneTaskRun(accuracyMetric, map[string]TaskResult{"a": {Metric: 0.8}})
+	after := oneTaskRun(accuracyMetric, map[string]TaskResult{"a": {Metric: 0.1}})
+
+	renamed := after
+	renamed.Tasks = []TaskRecord{after.Tasks[0]}
+	renamed.Tasks[0].Name = "other"
+	if got := FindRegressions(before, renamed, 0.01); got != nil {
+		t.Errorf("task missing from the baseline: got %+v", got)
+	}
+	cancelled := after
+	cancelled.Tasks = []TaskRecord{{Number: 1, Name: "task", Metric: accuracyMetric, Cancelled: true}}
+	if got := FindRegressions(before, cancelled, 0.01); got != nil {
+		t.Errorf("cancelled task: got %+v", got)
+	}
+	if got := FindRegressions(cancelled, after, 0.01); got != nil {
+		t.Errorf("task cancelled in the baseline: got %+v", got)
+	}
+}
+
+func TestRegressionString(t *testing.T) {
+	tests := []struct {
+		regression Regression
+		want       string
+	}{
+		{Regression{TaskName: "t", Model: "m", Metric: "Accuracy", Baseline: 0.8, Current: 0.7}, "t: m: Accuracy 0.8000 -> 0.7000 (-0.1000)"},
+		{Regression{TaskName: "t", Model: "m", Audit: true}, "t: m: now fails the audit"},
+	}
+	for _, tt := range tests {
+		if got := tt.regression.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestHistory(t *testing.T) {
+	history := NewHistory(filepath.Join(t.TempDir(), "history"))
+	if runs, err := history.Runs(); err != nil || len(runs) != 0 {
+		t.Fatalf("empty history: runs %v, err %v", runs, err)
+	}
+	if _, err := history.Latest(); err == nil {
+		t.Error("Latest of an empty history succeeded")
+	}
+
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	var saved []string
+	// Saved out of order, and the later run in another zone.
+	for _, started := range []time.Time{start.Add(time.Hour).In(time.FixedZone("east", 5*3600)), start} {
+		path, err := history.Save(RunRecord{Started: started, Models: []string{"a"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		saved = append(saved, path)
+	}
+	runs, err := history.Runs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{saved[1], saved[0]}; !reflect.DeepEqual(runs, want) {
+		t.Errorf("runs = %v, want oldest first %v", runs, want)
+	}
+	latest, err := history.Latest()
+	if err != nil || latest != saved[0] {
+		t.Errorf("Latest = %s, %v, want %s", latest, err, saved[0])
+	}
+	record, err := LoadRun(latest)
+	if err != nil || !record.Started.Equal(start.Add(time.Hour)) {
+		t.Errorf("loaded run started %v, %v, want %v", record.Started, err, start.Add(time.Hour))
+	}
+}
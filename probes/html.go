@@ -0,0 +1,343 @@
+package probes
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// histogramBins is the number of bins spanning [-1, 1] in similarity
+// histograms.
+const histogramBins = 20
+
+// Chart colours. Winners are green, baselines grey and results that failed
+// the audit red.
+const (
+	colorModel    = "#1f77b4"
+	colorWinner   = "#2e7d32"
+	colorBaseline = "#9e9e9e"
+	colorFailed   = "#c62828"
+)
+
+// labelColors colour histogram series, one per actual label.
+var labelColors = []string{"#1f77b4", "#ff7f0e", "#9467bd", "#8c564b", "#17becf"}
+
+// htmlTask is one task's section of the HTML report.
+type htmlTask struct {
+	TaskRecord
+	Chart      template.HTML
+	Histograms []template.HTML
+	Models     []htmlModel
+}
+
+// htmlModel is one model's drill-down in a task section.
+type htmlModel struct {
+	Name     string
+	Score    string
+	Winner   bool
+	Baseline bool
+	Result   TaskResult
+}
+
+// htmlReport is the data behind reportTemplate.
+type htmlReport struct {
+	Record  RunRecord
+	Started string
+	Took    string
+	Groups  []taskGroup
+	Tasks   []htmlTask
+	Wins    []htmlWins
+}
+
+type htmlWins struct {
+	Model       string
+	Wins        int
+	Significant int
+}
+
+// exportHTML writes the run as a single HTML page with inline SVG charts and
+// no external resources, so it can be opened offline or attached to a
+// ticket.
+func exportHTML(w io.Writer, record RunRecord) error {
+	report := htmlReport{
+		Record:  record,
+		Started: record.Started.Format(time.RFC3339),
+		Took:    record.Duration.Round(time.Millisecond).String(),
+		Groups:  groupTasks(record),
+	}
+	for _, task := range record.Tasks {
+		section := htmlTask{TaskRecord: task}
+		if !task.Cancelled {
+			section.Chart = barChart(record, task)
+			if task.SimilarityScores {
+				for _, model := range record.Models {
+					if result, ok := task.Results[model]; ok {
+						section.Histograms = append(section.Histograms, histogram(model, result.Items))
+					}
+				}
+			}
+		}
+		for _, model := range record.Models {
+			if result, ok := task.Results[model]; ok {
+				section.Models = append(section.Models, htmlModel{
+					Name:     model,
+					Score:    FormatScore(result),
+					Winner:   task.IsWinner(model),
+					Baseline: record.IsBaseline(model),
+					Result:   result,
+				})
+			}
+		}
+		report.Tasks = append(report.Tasks, section)
+	}
+	wins, significant := record.Wins()
+	for _, model := range record.ModelNames() {
+		report.Wins = append(report.Wins, htmlWins{model, wins[model], significant[model]})
+	}
+	return reportTemplate.Execute(w, report)
+}
+
+// chartRange returns the value range a task's bar chart spans: the metric's
+// bounds where finite, else the scores themselves and zero.
+func chartRange(metric MetricDescriptor, values []float64) (lo, hi float64) {
+	lo, hi = 0, 0
+	for _, v := range values {
+		lo, hi = math.Min(lo, v), math.Max(hi, v)
+	}
+	if !math.IsInf(metric.Min, 0) {
+		lo = metric.Min
+	}
+	if !math.IsInf(metric.Max, 0) {
+		hi = metric.Max
+	}
+	if hi <= lo {
+		hi = lo + 1
+	}
+	return lo, hi
+}
+
+// barChart draws one horizontal bar per model, with its confidence interval
+// as a whisker.
+func barChart(record RunRecord, task TaskRecord) template.HTML {
+	const labelWidth, plotWidth, valueWidth, rowHeight = 170.0, 380.0, 130.0, 26.0
+
+	var models []string
+	var values []float64
+	for _, model := range record.Models {
+		if result, ok := task.Results[model]; ok {
+			models = append(models, model)
+			values = append(values, result.Metric)
+			if result.CI != nil {
+				values = append(values, result.CI.Low, result.CI.High)
+			}
+		}
+	}
+	lo, hi := chartRange(task.Metric, values)
+	x := func(v float64) float64 {
+		v = math.Max(lo, math.Min(hi, v))
+		return labelWidth + (v-lo)/(hi-lo)*plotWidth
+	}
+	zero := x(0)
+
+	var b strings.Builder
+	height := rowHeight*float64(len(models)) + 20
+	fmt.Fprintf(&b, `<svg class="chart" xmlns="http://www.w3.org/2000/svg" width="%.0f" height="%.0f" role="img" aria-label="%s by model">`,
+		labelWidth+plotWidth+valueWidth, height, html.EscapeString(task.Metric.Name))
+	for i, model := range models {
+		result := task.Results[model]
+		y := float64(i) * rowHeight
+		color := colorModel
+		switch {
+		case len(result.AuditFailures) > 0:
+			color = colorFailed
+		case record.IsBaseline(model):
+			color = colorBaseline
+		case task.IsWinner(model):
+			color = colorWinner
+		}
+		left, right := math.Min(zero, x(result.Metric)), math.Max(zero, x(result.Metric))
+		fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" text-anchor="end">%s</text>`, labelWidth-6, y+17, html.EscapeString(model))
+		fmt.Fprintf(&b, `<rect x="%.1f" y="%.1f" width="%.1f" height="18" fill="%s"><title>%s: %s</title></rect>`,
+			left, y+4, math.Max(right-left, 1), color, html.EscapeString(model), FormatScore(result))
+		if ci := result.CI; ci != nil {
+			fmt.Fprintf(&b, `<line x1="%.1f" x2="%.1f" y1="%.1f" y2="%.1f" stroke="#000"/>`, x(ci.Low), x(ci.High), y+13, y+13)
+			for _, v := range []float64{ci.Low, ci.High} {
+				fmt.Fprintf(&b, `<line x1="%.1f" x2="%.1f" y1="%.1f" y2="%.1f" stroke="#000"/>`, x(v), x(v), y+8, y+18)
+			}
+		}
+		fmt.Fprintf(&b, `<text x="%.1f" y="%.1f">%.4f</text>`, labelWidth+plotWidth+6, y+17, result.Metric)
+	}
+	axisY := rowHeight * float64(len(models))
+	fmt.Fprintf(&b, `<line x1="%.1f" x2="%.1f" y1="0" y2="%.1f" stroke="#555"/>`, zero, zero, axisY)
+	fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" class="axis">%g</text>`, labelWidth, axisY+14, lo)
+	fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" class="axis" text-anchor="end">%g</text>`, labelWidth+plotWidth, axisY+14, hi)
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
+
+// histogram draws the distribution of a model's item similarities over
+// [-1, 1], stacked by actual label when the items have one.
+func histogram(model string, items []ItemResult) template.HTML {
+	const width, height, top, bottom = 280.0, 150.0, 20.0, 20.0
+
+	counts := make(map[string][]int)
+	var labels []string
+	for _, item := range items {
+		label := item.Actual
+		if _, ok := counts[label]; !ok {
+			counts[label] = make([]int, histogramBins)
+			labels = append(labels, label)
+		}
+		bin := int((item.Score + 1) / 2 * histogramBins)
+		bin = max(0, min(histogramBins-1, bin))
+		counts[label][bin]++
+	}
+	sort.Strings(labels)
+	tallest := 1
+	for bin := 0; bin < histogramBins; bin++ {
+		total := 0
+		for _, label := range labels {
+			total += counts[label][bin]
+		}
+		tallest = max(tallest, total)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg class="histogram" xmlns="http://www.w3.org/2000/svg" width="%.0f" height="%.0f" role="img" aria-label="%s similarity histogram">`,
+		width, height, html.EscapeString(model))
+	fmt.Fprintf(&b, `<text x="0" y="13">%s</text>`, html.EscapeString(model))
+	plotHeight := height - top - bottom
+	barWidth := width / histogramBins
+	for bin := 0; bin < histogramBins; bin++ {
+		y := height - bottom
+		for i, label := range labels {
+			n := counts[label][bin]
+			if n == 0 {
+				continue
+			}
+			h := float64(n) / float64(tallest) * plotHeight
+			y -= h
+			lo := float64(bin)/histogramBins*2 - 1
+			fmt.Fprintf(&b, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s"><title>%s [%.1f, %.1f): %d</title></rect>`,
+				float64(bin)*barWidth, y, barWidth-1, h, labelColors[i%len(labelColors)],
+				html.EscapeString(histogramLabel(label)), lo, lo+2.0/histogramBins, n)
+		}
+	}
+	fmt.Fprintf(&b, `<text x="0" y="%.0f" class="axis">-1</text>`, height-4)
+	fmt.Fprintf(&b, `<text x="%.0f" y="%.0f" class="axis" text-anchor="middle">0</text>`, width/2, height-4)
+	fmt.Fprintf(&b, `<text x="%.0f" y="%.0f" class="axis" text-anchor="end">1</text>`, width, height-4)
+	if len(labels) > 1 {
+		x := width
+		for i := len(labels) - 1; i >= 0; i-- {
+			fmt.Fprintf(&b, `<text x="%.0f" y="13" class="axis" text-anchor="end" fill="%s">%s</text>`,
+				x, labelColors[i%len(labelColors)], html.EscapeString(labels[i]))
+			x -= float64(len(labels[i]))*7 + 10
+		}
+	}
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
+
+func histogramLabel(label string) string {
+	if label == "" {
+		return "items"
+	}
+	return label
+}
+
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"score": FormatScore,
+	// result returns the model's result in a task, or nil when the task
+	// was cancelled or has none, since a TaskResult value is never empty
+	// to "with".
+	"result": func(task TaskRecord, model string) *TaskResult {
+		if result, ok := task.Results[model]; ok && !task.Cancelled {
+			return &result
+		}
+		return nil
+	},
+	"float": func(v float64) string { return fmt.Sprintf("%.4f", v) },
+	"gold": func(v *float64) string {
+		if v == nil {
+			return ""
+		}
+		return fmt.Sprintf("%g", *v)
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Embedding Probes Report</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin: 0.5em 0 1em; }
+th, td { border: 1px solid #ccc; padding: 0.25em 0.6em; text-align: left; vertical-align: top; }
+td.num { text-align: right; font-variant-numeric: tabular-nums; }
+td.winner { font-weight: bold; background: #e8f5e9; }
+td.failed { color: #c62828; }
+section { border-top: 1px solid #ddd; margin-top: 2em; }
+svg text { font-size: 12px; }
+svg .axis { font-size: 10px; fill: #555; }
+.histograms { display: flex; flex-wrap: wrap; gap: 1em; }
+.audit { color: #c62828; }
+details { margin: 0.3em 0; }
+td.text { max-width: 40em; font-size: 0.9em; }
+</style>
+</head>
+<body>
+<h1>Embedding Probes Report</h1>
+<p>Run started {{.Started}} and took {{.Took}}.{{with .Record.Baselines}} Baselines, which never win: {{range $i, $b := .}}{{if $i}}, {{end}}{{$b}}{{end}}.{{end}}</p>
+{{with .Record.Identities}}
+<table>
+<tr><th>Model</th><th>Backend</th><th>Model ID</th><th>Digest</th><th>Server</th></tr>
+{{range $name, $id := .}}<tr><td>{{$name}}</td><td>{{$id.Backend}}</td><td>{{$id.Model}}</td><td>{{$id.Digest}}</td><td>{{$id.BaseURL}}</td></tr>
+{{end}}</table>
+{{end}}
+{{$record := .Record}}
+{{range .Groups}}
+<h2>{{.Category}}</h2>
+<table>
+<tr><th>Task</th><th>Task Name</th><th>Metric</th>{{range $record.Models}}<th>{{.}}</th>{{end}}<th>Winner</th></tr>
+{{range $task := .Tasks}}<tr><td class="num">{{.Number}}</td><td><a href="#task-{{.Number}}">{{.Name}}</a></td><td>{{.Metric.Name}}</td>
+{{- range $model := $record.Models}}{{with result $task $model}}<td class="num{{if $task.IsWinner $model}} winner{{end}}{{if .AuditFailures}} failed{{end}}">{{score .}}</td>{{else}}<td>{{if $task.Cancelled}}cancelled{{end}}</td>{{end}}{{end}}
+<td>{{if .Cancelled}}cancelled{{else}}{{.Outcome}}{{end}}</td></tr>
+{{end}}</table>
+{{end}}
+<h2>Wins</h2>
+<table>
+<tr><th>Model</th><th>Wins</th><th>Significant</th></tr>
+{{range .Wins}}<tr><td>{{.Model}}</td><td class="num">{{.Wins}}</td><td class="num">{{.Significant}}</td></tr>
+{{end}}</table>
+{{range .Tasks}}
+<section id="task-{{.Number}}">
+<h3>Task {{.Number}}: {{.Name}}</h3>
+{{if .Cancelled}}<p>Cancelled.</p>{{else}}
+<p>{{.Metric.Name}}, {{.Metric.Direction}}. Winner: {{.Outcome}}.</p>
+{{with .Outcome.Pairwise}}<table>
+<tr><th>Better</th><th>Worse</th><th>p-value</th></tr>
+{{range .}}<tr><td>{{.Better}}</td><td>{{.Worse}}</td><td class="num">{{float .PValue}}</td></tr>
+{{end}}</table>{{end}}
+{{.Chart}}
+{{with .Histograms}}<h4>Similarity distributions</h4>
+<div class="histograms">{{range .}}{{.}}{{end}}</div>{{end}}
+{{range .Models}}{{range .Result.AuditFailures}}<p class="audit">Audit failure: {{.}}</p>{{end}}{{end}}
+{{range .Models}}
+<details>
+<summary>{{.Name}}: {{.Score}}{{if .Winner}} (winner){{end}}{{if .Baseline}} (baseline){{end}}, {{len .Result.Items}} items</summary>
+{{with .Result.SubMetrics}}<table>{{range .}}<tr><td>{{.Name}}</td><td class="num">{{float .Value}}</td></tr>{{end}}</table>{{end}}
+{{with .Result.Items}}<table>
+<tr><th>ID</th><th>Score</th><th>Predicted</th><th>Actual</th><th>Gold</th><th>Texts</th></tr>
+{{range .}}<tr><td>{{.ID}}</td><td class="num">{{float .Score}}</td><td>{{.Predicted}}</td><td>{{.Actual}}</td><td class="num">{{gold .Gold}}</td><td class="text">{{range $i, $t := .Texts}}{{if $i}}<br>{{end}}{{$t}}{{end}}</td></tr>
+{{end}}</table>{{end}}
+</details>
+{{end}}{{end}}
+</section>
+{{end}}
+</body>
+</html>
+`))
@@ -0,0 +1,66 @@
+package probes
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// summaryRow returns the cells of task's row in the report's summary table.
+func summaryRow(t *testing.T, page, taskName string) []string {
+	t.Helper()
+	row := regexp.MustCompile(`(?s)<tr><td class="num">\d+</td><td><a href="#task-\d+">` + regexp.QuoteMeta(taskName) + `</a></td>.*?</tr>`).FindString(page)
+	if row == "" {
+		t.Fatalf("no summary row for %s", taskName)
+	}
+	var cells []string
+	for _, m := range regexp.MustCompile(`(?s)<td[^>]*>(.*?)</td>`).FindAllStringSubmatch(row, -1) {
+		cells = append(cells, m[1])
+	}
+	return cells
+}
+
+func TestHTMLSummaryCells(t *testing.T) {
+	metric := MetricDescriptor{Name: "Accuracy", Direction: HigherIsBetter, Min: 0, Max: 1}
+	record := RunRecord{
+		Models: []string{"a", "b"},
+		Tasks: []TaskRecord{
+			{Number: 1, Name: "Done", Metric: metric,
+				Results: map[string]TaskResult{"a": {Metric: 0.75}, "b": {Metric: 0.5}},
+				Outcome: Outcome{Winners: []string{"a"}}},
+			{Number: 2, Name: "Missing", Metric: metric,
+				Results: map[string]TaskResult{"a": {Metric: 0.25}},
+				Outcome: Outcome{Winners: []string{"a"}}},
+			{Number: 3, Name: "Stopped", Metric: metric, Cancelled: true},
+		},
+	}
+	var buf bytes.Buffer
+	if err := ExportRun(&buf, record, FormatHTML); err != nil {
+		t.Fatal(err)
+	}
+	page := buf.String()
+
+	tests := []struct {
+		task string
+		want []string
+	}{
+		{"Done", []string{"0.7500", "0.5000"}},
+		{"Missing", []string{"0.2500", ""}},
+		{"Stopped", []string{"cancelled", "cancelled", "cancelled"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.task, func(t *testing.T) {
+			// Skip the number, name and metric cells.
+			cells := summaryRow(t, page, tt.task)[3:]
+			for i, want := range tt.want {
+				if got := strings.TrimSpace(cells[i]); got != want {
+					t.Errorf("cell %d = %q, want %q", i, got, want)
+				}
+			}
+			if strings.Contains(strings.Join(cells, ""), "0.0000") {
+				t.Errorf("row %v shows a zero score for a missing result", cells)
+			}
+		})
+	}
+}
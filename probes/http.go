@@ -0,0 +1,193 @@
+package probes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Defaults for EmbedderConfig.Timeout and EmbedderConfig.MaxRetries.
+const (
+	DefaultRequestTimeout = 2 * time.Minute
+	DefaultMaxRetries     = 3
+)
+
+// The backoff before retry n is drawn from [0, retryBaseDelay*2^n], capped
+// at retryMaxDelay. They are variables so tests can shorten them.
+var (
+	retryBaseDelay = 500 * time.Millisecond
+	retryMaxDelay  = 10 * time.Second
+)
+
+// endpoint holds the HTTP settings shared by the network backends.
+type endpoint struct {
+	client     *http.Client
+	apiKey     string
+	headers    map[string]string
+	timeout    time.Duration
+	maxRetries int
+}
+
+func newEndpoint(cfg EmbedderConfig) endpoint {
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = DefaultRequestTimeout
+	}
+	maxRetries := cfg.MaxRetries
+	if maxRetries < 0 {
+		maxRetries = 0
+	} else if maxRetries == 0 {
+		maxRetries = DefaultMaxRetries
+	}
+	return endpoint{
+		client:     &http.Client{},
+		apiKey:     cfg.APIKey,
+		headers:    cfg.Headers,
+		timeout:    timeout,
+		maxRetries: maxRetries,
+	}
+}
+
+// postJSON sends payload as JSON to url and decodes the response into out.
+func (ep endpoint) postJSON(ctx context.Context, url string, payload, out any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("error marshaling payload: %v", err)
+	}
+	return ep.do(ctx, http.MethodPost, url, body, out)
+}
+
+// getJSON fetches url and decodes the response into out.
+func (ep endpoint) getJSON(ctx context.Context, url string, out any) error {
+	return ep.do(ctx, http.MethodGet, url, nil, out)
+}
+
+// do performs the request, retrying transient failures with exponential
+// backoff and full jitter. Each attempt gets its own timeout.
+func (ep endpoint) do(ctx context.Context, method, url string, body []byte, out any) error {
+	var err error
+	for attempt := 0; ; attempt++ {
+		err = ep.attempt(ctx, method, url, body, out)
+		if err == nil || attempt >= ep.maxRetries || !isRetryable(ctx, err) {
+			return err
+		}
+
+		delay := time.Duration(rand.Int64N(int64(backoffLimit(attempt)) + 1))
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w (gave up retrying: %v)", err, ctx.Err())
+		case <-time.After(delay):
+		}
+	}
+}
+
+// backoffLimit returns the longest delay before the retry that follows the
+// given zero-based attempt.
+func backoffLimit(attempt int) time.Duration {
+	if attempt >= 30 || retryBaseDelay<<attempt > retryMaxDelay {
+		return retryMaxDelay
+	}
+	return retryBaseDelay << attempt
+}
+
+func (ep endpoint) attempt(ctx context.Context, method, url string, body []byte, out any) error {
+	ctx, cancel := context.WithTimeout(ctx, ep.timeout)
+	defer cancel()
+
+	var reqBody io.Reader
+	if body != nil {
+		reqBody = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
+	if err != nil {
+		return fmt.Errorf("error creating request: %v", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if ep.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+ep.apiKey)
+	}
+	for key, value := range ep.headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := ep.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error making request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("error reading response: %w", err)
+	}
+
+	success := resp.StatusCode >= 200 && resp.StatusCode <= 299
+	if message := errorMessage(respBody); message != "" {
+		if success {
+			// Some servers report failures with a 200 and an error body.
+			return newAPIError(0, message)
+		}
+		return newAPIError(resp.StatusCode, message)
+	}
+	if !success {
+		return newAPIError(resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("%w: error unmarshaling response: %v", ErrBadResponse, err)
+	}
+	return nil
+}
+
+// errorMessage extracts the error from an Ollama {"error": "..."} or OpenAI
+// {"error": {"message": "..."}} body. It returns "" for any other body.
+func errorMessage(body []byte) string {
+	var envelope struct {
+		Error json.RawMessage `json:"error"`
+	}
+	if json.Unmarshal(body, &envelope) != nil || len(envelope.Error) == 0 || string(envelope.Error) == "null" {
+		return ""
+	}
+	var message string
+	if json.Unmarshal(envelope.Error, &message) == nil {
+		return message
+	}
+	var detail struct {
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(envelope.Error, &detail) == nil && detail.Message != "" {
+		return detail.Message
+	}
+	return string(envelope.Error)
+}
+
+// isRetryable reports whether err is worth another attempt: overloaded or
+// failing servers (429 and 5xx), refused or reset connections and timeouts.
+// Other transport failures, such as unknown hosts, TLS errors and bad URLs,
+// would fail the same way again. Nothing is retried once ctx itself is done.
+func isRetryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if errors.Is(err, ErrServerOverloaded) {
+		return true
+	}
+	if errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) {
+		return true
+	}
+	// The per-attempt timeout surfaces as context.DeadlineExceeded, which is
+	// a net.Error, wrapped in a *url.Error.
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
@@ -0,0 +1,229 @@
+package probes
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// fastRetries shortens the retry backoff for the duration of the test.
+func fastRetries(t *testing.T) {
+	t.Helper()
+	base, max := retryBaseDelay, retryMaxDelay
+	retryBaseDelay, retryMaxDelay = time.Millisecond, 4*time.Millisecond
+	t.Cleanup(func() { retryBaseDelay, retryMaxDelay = base, max })
+}
+
+// statusServer answers each request with the next status and body in turn,
+// repeating the last one, and counts the requests.
+func statusServer(t *testing.T, statuses []int, bodies []string) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		i := min(int(hits.Add(1))-1, len(statuses)-1)
+		w.WriteHeader(statuses[i])
+		w.Write([]byte(bodies[i]))
+	}))
+	t.Cleanup(server.Close)
+	return server, &hits
+}
+
+func TestEndpointRetries(t *testing.T) {
+	fastRetries(t)
+	tests := []struct {
+		name       string
+		statuses   []int
+		bodies     []string
+		maxRetries int
+		wantHits   int32
+		want       error
+	}{
+		{"recovers from 503", []int{503, 503, 200}, []string{"busy", "busy", "{}"}, 3, 3, nil},
+		{"recovers from 429", []int{429, 200}, []string{"slow down", "{}"}, 3, 2, nil},
+		{"gives up after max retries", []int{500}, []string{"boom"}, 2, 3, ErrServerOverloaded},
+		{"default retries", []int{502}, []string{"bad gateway"}, 0, DefaultMaxRetries + 1, ErrServerOverloaded},
+		{"retries disabled", []int{503}, []string{"busy"}, -1, 1, ErrServerOverloaded},
+		{"model not found", []int{404}, []string{`{"error": "model \"x\" not found"}`}, 3, 1, ErrModelNotFound},
+		{"model not found with 500", []int{500}, []string{`{"error": "model \"x\" not found"}`}, 3, 1, ErrModelNotFound},
+		{"plain 404", []int{404}, []string{"404 page not found"}, 3, 1, ErrBadResponse},
+		{"bad request", []int{400}, []string{`{"error": {"message": "input too long"}}`}, 3, 1, ErrBadResponse},
+		{"bad body", []int{200}, []string{"not json"}, 3, 1, ErrBadResponse},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, hits := statusServer(t, tt.statuses, tt.bodies)
+			ep := newEndpoint(EmbedderConfig{MaxRetries: tt.maxRetries})
+			var out struct{}
+			err := ep.getJSON(context.Background(), server.URL, &out)
+			if tt.want == nil && err != nil || !errors.Is(err, tt.want) {
+				t.Errorf("error = %v, want %v", err, tt.want)
+			}
+			if got := hits.Load(); got != tt.wantHits {
+				t.Errorf("got %d requests, want %d", got, tt.wantHits)
+			}
+		})
+	}
+}
+
+func TestEndpointModelNotFoundIsTyped(t *testing.T) {
+	server, _ := statusServer(t, []int{404}, []string{`{"error": {"message": "The model 'x' does not exist"}}`})
+	var out struct{}
+	err := newEndpoint(EmbedderConfig{}).getJSON(context.Background(), server.URL, &out)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != 404 || apiErr.Message != "The model 'x' does not exist" {
+		t.Fatalf("error = %#v, want an *APIError with status 404", err)
+	}
+	if !errors.Is(err, ErrModelNotFound) {
+		t.Errorf("error = %v, want %v", err, ErrModelNotFound)
+	}
+}
+
+func TestBackoffLimit(t *testing.T) {
+	want := []time.Duration{
+		500 * time.Millisecond, time.Second, 2 * time.Second, 4 * time.Second,
+		8 * time.Second, 10 * time.Second, 10 * time.Second,
+	}
+	for attempt, limit := range want {
+		if got := backoffLimit(attempt); got != limit {
+			t.Errorf("backoffLimit(%d) = %v, want %v", attempt, got, limit)
+		}
+	}
+	if got := backoffLimit(100); got != retryMaxDelay {
+		t.Errorf("backoffLimit(100) = %v, want %v", got, retryMaxDelay)
+	}
+}
+
+func TestEndpointTimeoutIsRetried(t *testing.T) {
+	fastRetries(t)
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) == 1 {
+			<-r.Context().Done()
+			return
+		}
+		w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+
+	ep := newEndpoint(EmbedderConfig{Timeout: 50 * time.Millisecond})
+	var out struct{}
+	if err := ep.getJSON(context.Background(), server.URL, &out); err != nil {
+		t.Fatal(err)
+	}
+	if got := hits.Load(); got != 2 {
+		t.Errorf("got %d requests, want 2", got)
+	}
+}
+
+func TestEndpointCancel(t *testing.T) {
+	t.Run("during request", func(t *testing.T) {
+		fastRetries(t)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		var hits atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			hits.Add(1)
+			cancel()
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		var out struct{}
+		if err := newEndpoint(EmbedderConfig{}).getJSON(ctx, server.URL, &out); err == nil {
+			t.Fatal("expected an error")
+		}
+		if got := hits.Load(); got != 1 {
+			t.Errorf("got %d requests after cancelling, want 1", got)
+		}
+	})
+
+	t.Run("during backoff", func(t *testing.T) {
+		// The backoff is far longer than the test, so only the
+		// cancellation can end it.
+		base := retryBaseDelay
+		retryBaseDelay = time.Hour
+		defer func() { retryBaseDelay = base }()
+		server, hits := statusServer(t, []int{503}, []string{"busy"})
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		var out struct{}
+		err := newEndpoint(EmbedderConfig{}).getJSON(ctx, server.URL, &out)
+		if err == nil || !strings.Contains(err.Error(), "gave up retrying") || !errors.Is(err, ErrServerOverloaded) {
+			t.Errorf("error = %v, want the last failure and a note that retrying stopped", err)
+		}
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Errorf("returned after %v, want soon after the deadline", elapsed)
+		}
+		if got := hits.Load(); got != 1 {
+			t.Errorf("got %d requests, want 1", got)
+		}
+	})
+}
+
+func TestIsRetryable(t *testing.T) {
+	// A closed server's address refuses connections.
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closedURL := closed.URL
+	closed.Close()
+	// A plain client does not trust the test server's certificate.
+	tlsServer := httptest.NewUnstartedServer(http.NotFoundHandler())
+	tlsServer.Config.ErrorLog = log.New(io.Discard, "", 0)
+	tlsServer.StartTLS()
+	defer tlsServer.Close()
+
+	transportError := func(rawURL string) error {
+		var out struct{}
+		return newEndpoint(EmbedderConfig{MaxRetries: -1}).getJSON(context.Background(), rawURL, &out)
+	}
+	dnsError := &url.Error{Op: "Get", URL: "http://nosuchhost.invalid", Err: &net.OpError{
+		Op: "dial", Net: "tcp", Err: &net.DNSError{Err: "no such host", Name: "nosuchhost.invalid", IsNotFound: true},
+	}}
+	resetError := &url.Error{Op: "Post", URL: "http://host", Err: &net.OpError{
+		Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET),
+	}}
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		err  error
+		want bool
+	}{
+		{"connection refused", context.Background(), transportError(closedURL), true},
+		{"connection reset", context.Background(), resetError, true},
+		{"attempt timeout", context.Background(), &url.Error{Op: "Get", URL: "http://host", Err: context.DeadlineExceeded}, true},
+		{"503", context.Background(), newAPIError(503, "busy"), true},
+		{"429", context.Background(), newAPIError(429, "slow down"), true},
+		{"unknown host", context.Background(), dnsError, false},
+		{"TLS failure", context.Background(), transportError(tlsServer.URL), false},
+		{"unsupported scheme", context.Background(), transportError("ftp://host/file"), false},
+		{"model not found", context.Background(), newAPIError(404, "model not found"), false},
+		{"400", context.Background(), newAPIError(400, "input too long"), false},
+		{"bad response", context.Background(), ErrBadResponse, false},
+		{"context done", cancelled, newAPIError(503, "busy"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.err == nil {
+				t.Fatal("no error to classify")
+			}
+			if got := isRetryable(tt.ctx, tt.err); got != tt.want {
+				t.Errorf("isRetryable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package probes
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// taskGroup is the tasks of one category, in run order.
+type taskGroup struct {
+	Category string
+	Tasks    []TaskRecord
+}
+
+// groupTasks groups the run's tasks by category, in order of each
+// category's first task. Runs saved before tasks had categories form one
+// "Tasks" group.
+func groupTasks(record RunRecord) []taskGroup {
+	var groups []taskGroup
+	index := make(map[string]int)
+	for _, task := range record.Tasks {
+		category := task.Category
+		if category == "" {
+			category = "Tasks"
+		}
+		i, ok := index[category]
+		if !ok {
+			i = len(groups)
+			index[category] = i
+			groups = append(groups, taskGroup{Category: category})
+		}
+		groups[i].Tasks = append(groups[i].Tasks, task)
+	}
+	return groups
+}
+
+// FormatScore renders a metric with its confidence interval when known, or
+// marks a result that failed the audit.
+func FormatScore(result TaskResult) string {
+	if len(result.AuditFailures) > 0 {
+		return "failed audit"
+	}
+	if result.CI == nil {
+		return fmt.Sprintf("%.4f", result.Metric)
+	}
+	return fmt.Sprintf("%.4f [%.2f, %.2f]", result.Metric, result.CI.Low, result.CI.High)
+}
+
+// IsWinner reports whether model is among the task's winners.
+func (t TaskRecord) IsWinner(model string) bool {
+	for _, name := range t.Outcome.Winners {
+		if name == model {
+			return true
+		}
+	}
+	return false
+}
+
+// markdownEscape keeps text from breaking a Markdown table cell.
+func markdownEscape(text string) string {
+	text = strings.ReplaceAll(text, "\\", "\\\\")
+	text = strings.ReplaceAll(text, "|", "\\|")
+	return strings.ReplaceAll(text, "\n", " ")
+}
+
+// exportMarkdown writes the run as GitHub-flavoured Markdown: one results
+// table per task category with winning scores in bold, then the win tally
+// and any audit failures.
+func exportMarkdown(w io.Writer, record RunRecord) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Embedding Probes Report\n\n")
+	fmt.Fprintf(&b, "Run started %s and took %s.", record.Started.Format(time.RFC3339), record.Duration.Round(time.Millisecond))
+	if len(record.Baselines) > 0 {
+		fmt.Fprintf(&b, " Baselines (never winners): %s.", strings.Join(record.Baselines, ", "))
+	}
+	b.WriteString("\n")
+
+	writeRow := func(cells []string) {
+		b.WriteString("|")
+		for _, cell := range cells {
+			b.WriteString(" " + cell + " |")
+		}
+		b.WriteString("\n")
+	}
+	for _, group := range groupTasks(record) {
+		fmt.Fprintf(&b, "\n## %s\n\n", group.Category)
+		header := []string{"Task", "Task Name", "Metric"}
+		for _, model := range record.Models {
+			header = append(header, markdownEscape(model))
+		}
+		writeRow(append(header, "Winner"))
+		separator := []string{"---:", "---", "---"}
+		for range record.Models {
+			separator = append(separator, "---:")
+		}
+		writeRow(append(separator, "---"))
+		for _, task := range group.Tasks {
+			row := []string{fmt.Sprintf("%d", task.Number), markdownEscape(task.Name), markdownEscape(task.Metric.Name)}
+			for _, model := range record.Models {
+				result, ok := task.Results[model]
+				switch {
+				case task.Cancelled:
+					row = append(row, "cancelled")
+				case !ok:
+					row = append(row, "")
+				case task.IsWinner(model):
+					row = append(row, "**"+FormatScore(result)+"**")
+				default:
+					row = append(row, FormatScore(result))
+				}
+			}
+			if task.Cancelled {
+				row = append(row, "cancelled")
+			} else {
+				row = append(row, markdownEscape(task.Outcome.String()))
+			}
+			writeRow(row)
+		}
+	}
+
+	wins, significant := record.Wins()
+	b.WriteString("\n## Wins\n\n")
+	writeRow([]string{"Model", "Wins", "Significant"})
+	writeRow([]string{"---", "---:", "---:"})
+	for _, model := range record.ModelNames() {
+		writeRow([]string{markdownEscape(model), fmt.Sprintf("%d", wins[model]), fmt.Sprintf("%d", significant[model])})
+	}
+
+	var tests [][]string
+	for _, task := range record.Tasks {
+		for _, test := range task.Outcome.Pairwise {
+			tests = append(tests, []string{fmt.Sprintf("%d", task.Number), markdownEscape(task.Name),
+				markdownEscape(test.Better), markdownEscape(test.Worse), fmt.Sprintf("%.4f", test.PValue)})
+		}
+	}
+	if len(tests) > 0 {
+		b.WriteString("\n## Pairwise Significance\n\n")
+		writeRow([]string{"Task", "Task Name", "Better", "Worse", "p-value"})
+		writeRow([]string{"---:", "---", "---", "---", "---:"})
+		for _, row := range tests {
+			writeRow(row)
+		}
+	}
+
+	var failures []string
+	for _, task := range record.Tasks {
+		for _, model := range record.Models {
+			for _, failure := range task.Results[model].AuditFailures {
+				failures = append(failures, fmt.Sprintf("- Task %d: %s: %s: %s\n", task.Number, task.Name, model, failure))
+			}
+		}
+	}
+	if len(failures) > 0 {
+		b.WriteString("\n## Audit Failures\n\n")
+		b.WriteString(strings.Join(failures, ""))
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
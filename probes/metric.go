@@ -0,0 +1,142 @@
+package probes
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Direction tells whether larger or smaller metric values are better.
+type Direction int
+
+const (
+	HigherIsBetter Direction = iota
+	LowerIsBetter
+)
+
+func (d Direction) String() string {
+	if d == LowerIsBetter {
+		return "lower is better"
+	}
+	return "higher is better"
+}
+
+// MetricDescriptor describes a task's headline metric so the runner can
+// rank models without task-specific logic.
+type MetricDescriptor struct {
+	Name      string
+	Direction Direction
+	// Tolerance is the largest difference from the best score that still
+	// counts as a tie.
+	Tolerance float64
+	// Min and Max bound the valid values; use math.Inf for open ends.
+	Min float64
+	Max float64
+	// Control marks negative-control tasks, whose scores are shown only as
+	// a reference point; no model wins them.
+	Control bool
+}
+
+var accuracyMetric = MetricDescriptor{Name: "Accuracy", Direction: HigherIsBetter, Min: 0, Max: 1}
+
+// InRange reports whether v is a valid value for the metric.
+func (m MetricDescriptor) InRange(v float64) bool {
+	return !math.IsNaN(v) && v >= m.Min && v <= m.Max
+}
+
+// Better reports whether a beats b by more than the tie tolerance.
+func (m MetricDescriptor) Better(a, b float64) bool {
+	if m.Direction == LowerIsBetter {
+		a, b = -a, -b
+	}
+	return a-b > m.Tolerance+1e-12
+}
+
+// Outcome is the result of comparing models on one task.
+type Outcome struct {
+	// Winners holds every model tied for the best score, in the order the
+	// models were given. It is empty when no model has a valid score.
+	Winners []string `json:"winners"`
+	// PValue is set by TestSignificance when a single winner could be
+	// tested against the other models: the largest p-value of its tests in
+	// Pairwise.
+	PValue *float64 `json:"p_value,omitempty"`
+	// Pairwise holds a significance test for every pair of models with a
+	// valid result, set by TestSignificance.
+	Pairwise []PairwiseTest `json:"pairwise,omitempty"`
+	// Control is set for negative-control tasks.
+	Control bool `json:"control,omitempty"`
+}
+
+// Significant reports whether the single winner's lead is unlikely to be
+// noise. Untested winners count as significant.
+func (o Outcome) Significant() bool {
+	return o.PValue == nil || *o.PValue < SignificanceLevel
+}
+
+// Winner returns the single winning model, or "" on a tie or no result.
+func (o Outcome) Winner() string {
+	if len(o.Winners) != 1 {
+		return ""
+	}
+	return o.Winners[0]
+}
+
+func (o Outcome) String() string {
+	if o.Control {
+		return "Control"
+	}
+	switch len(o.Winners) {
+	case 0:
+		return "None"
+	case 1:
+		if !o.Significant() {
+			return fmt.Sprintf("%s (not significant, p=%.2f)", o.Winners[0], *o.PValue)
+		}
+		return o.Winners[0]
+	default:
+		return fmt.Sprintf("Tie (%s)", strings.Join(o.Winners, ", "))
+	}
+}
+
+// SelectWinners picks the best models for a task. Models are considered in
+// the given order, which makes ties deterministic; models without a result,
+// with a value outside the metric's range, or with audit failures are
+// skipped. Control tasks have no winners.
+func SelectWinners(metric MetricDescriptor, results map[string]TaskResult, models []string) Outcome {
+	if metric.Control {
+		return Outcome{Control: true}
+	}
+	var best float64
+	found := false
+	for _, model := range models {
+		result, ok := results[model]
+		if !ok || !result.valid(metric) {
+			continue
+		}
+		if !found || metric.Better(result.Metric, best) {
+			best = result.Metric
+			found = true
+		}
+	}
+
+	var outcome Outcome
+	if !found {
+		return outcome
+	}
+	for _, model := range models {
+		result, ok := results[model]
+		if !ok || !result.valid(metric) {
+			continue
+		}
+		if !metric.Better(best, result.Metric) {
+			outcome.Winners = append(outcome.Winners, model)
+		}
+	}
+	return outcome
+}
+
+// valid reports whether r can take part in winner selection.
+func (r TaskResult) valid(metric MetricDescriptor) bool {
+	return metric.InRange(r.Metric) && len(r.AuditFailures) == 0
+}
@@ -1,96 +0,0 @@
-package probes
-
-import (
-	"fmt"
-)
-
-type metricEvidenceTask struct{}
-
-func (t *metricEvidenceTask) Name() string {
-	return "Metric Evidence Task"
-}
-
-func (t *metricEvidenceTask) MetricName() string {
-	return "Accuracy"
-}
-
-func (t *metricEvidenceTask) Run(models []string) (map[string]TaskResult, error) {
-	// Define the metric and evidence chunks with ground truth labels
-	type Evidence struct {
-		text     string
-		relevant bool // True if supports Class A or B, False if Class C
-	}
-	metric := "How comprehensive are the organization’s employee wellness programs?"
-	evidenceChunks := []Evidence{
-		{
-			text:     "At Horizon Inc., we prioritize employee well-being with a holistic wellness program. This includes gym memberships, mental health counseling through an on-site therapist, and financial planning workshops held quarterly. Last year, we expanded with mindfulness sessions and a subsidized healthy meal plan, ensuring staff thrive in all aspects of life.",
-			relevant: true, // Class A: comprehensive
-		},
-		{
-			text:     "Our company values health and provides a fitness center at headquarters with an annual health fair. Employees enjoy discounted gym rates and a spring step challenge. While we focus on physical fitness, we’re exploring more offerings based on feedback from our yearly employee survey.",
-			relevant: true, // Class B: moderate
-		},
-		{
-			text:     "Horizon Inc. delivers top-quality products, with teams working diligently to meet deadlines. We recently upgraded our office with ergonomic furniture and a modern break room to enhance comfort during long hours, reflecting our commitment to a productive environment.",
-			relevant: false, // Class C: minimal/no wellness programs
-		},
-	}
-
-	results := make(map[string]TaskResult)
-
-	for _, model := range models {
-		fmt.Printf("Model: %s\n", model)
-		correct := 0
-		metricEmb, err := getEmbedding(model, metric)
-		if err != nil {
-			return nil, fmt.Errorf("error getting embedding for metric: %v", err)
-		}
-
-		for i, evidence := range evidenceChunks {
-			evidenceEmb, err := getEmbedding(model, metric)
-			if err != nil {
-				return nil, fmt.Errorf("error getting embedding for evidence %d: %v", i+1, err)
-			}
-
-			sim, err := cosineSimilarity(metricEmb, evidenceEmb)
-			if err != nil {
-				return nil, fmt.Errorf("error computing similarity for evidence %d: %v", i+1, err)
-			}
-
-			// Threshold: sim > 0.5 indicates relevance (tunable)
-			predictedRelevant := sim > 0.5
-			fmt.Printf("Evidence %d: Similarity=%.4f, Predicted=%v, Actual=%v\n", i+1, sim, predictedRelevant, evidence.relevant)
-			if predictedRelevant == evidence.relevant {
-				correct++
-			}
-		}
-
-		accuracy := float64(correct) / float64(len(evidenceChunks))
-		fmt.Printf("Accuracy: %.4f (%d/%d correct)\n", accuracy, correct, len(evidenceChunks))
-		results[model] = TaskResult{Metric: accuracy}
-	}
-
-	// Determine winner
-	maxAccuracy := -1.0
-	var winner string
-	for model, result := range results {
-		if result.Metric > maxAccuracy {
-			maxAccuracy = result.Metric
-			winner = model
-		}
-	}
-	for model := range results {
-		if model == winner {
-			result := results[model]
-			result.Winner = model
-			results[model] = result
-		}
-	}
-
-	return results, nil
-}
-
-func init() {
-	fmt.Println("Registering Metric Evidence Task")
-	RegisterTask(&metricEvidenceTask{})
-}
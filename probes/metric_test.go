@@ -0,0 +1,79 @@
+package probes
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestSelectWinners(t *testing.T) {
+	higher := MetricDescriptor{Name: "Score", Direction: HigherIsBetter, Tolerance: 0.01, Min: 0, Max: 1}
+	lower := MetricDescriptor{Name: "Error", Direction: LowerIsBetter, Tolerance: 0.01, Min: 0, Max: math.Inf(1)}
+	models := []string{"a", "b", "c"}
+	scores := func(values ...float64) map[string]TaskResult {
+		results := make(map[string]TaskResult)
+		for i, v := range values {
+			results[models[i]] = TaskResult{Metric: v}
+		}
+		return results
+	}
+	tests := []struct {
+		name    string
+		metric  MetricDescriptor
+		results map[string]TaskResult
+		want    []string
+	}{
+		{"single best", higher, scores(0.5, 0.9, 0.7), []string{"b"}},
+		{"tie within tolerance, later model best", higher, scores(0.895, 0.9, 0.7), []string{"a", "b"}},
+		{"tie within tolerance, earlier model best", higher, scores(0.9, 0.895, 0.7), []string{"a", "b"}},
+		{"just outside tolerance", higher, scores(0.88, 0.9, 0.7), []string{"b"}},
+		{"exact tie keeps config order", higher, scores(0.7, 0.7, 0.7), []string{"a", "b", "c"}},
+		{"lower is better", lower, scores(3, 1, 2), []string{"b"}},
+		{"lower is better tie, later model best", lower, scores(1.005, 1, 2), []string{"a", "b"}},
+		{"lower is better tie, earlier model best", lower, scores(1, 1.005, 2), []string{"a", "b"}},
+		{"out of range skipped", higher, scores(0.5, 1.5, math.NaN()), []string{"a"}},
+		{"missing result skipped", higher, map[string]TaskResult{"c": {Metric: 0.1}}, []string{"c"}},
+		{"audit failure skipped", higher, map[string]TaskResult{
+			"a": {Metric: 0.9, AuditFailures: []string{"constant"}},
+			"b": {Metric: 0.2},
+		}, []string{"b"}},
+		{"no valid result", higher, scores(-1, 2), nil},
+		{"no results", higher, nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outcome := SelectWinners(tt.metric, tt.results, models)
+			if !reflect.DeepEqual(outcome.Winners, tt.want) {
+				t.Errorf("Winners = %v, want %v", outcome.Winners, tt.want)
+			}
+			if outcome.Control {
+				t.Error("Control set for a non-control task")
+			}
+		})
+	}
+
+	control := higher
+	control.Control = true
+	if outcome := SelectWinners(control, scores(0.5, 0.9, 0.7), models); !outcome.Control || outcome.Winners != nil {
+		t.Errorf("control outcome = %+v, want Control and no winners", outcome)
+	}
+}
+
+func TestOutcomeString(t *testing.T) {
+	high := 0.2
+	tests := []struct {
+		outcome Outcome
+		want    string
+	}{
+		{Outcome{}, "None"},
+		{Outcome{Control: true}, "Control"},
+		{Outcome{Winners: []string{"a"}}, "a"},
+		{Outcome{Winners: []string{"a"}, PValue: &high}, "a (not significant, p=0.20)"},
+		{Outcome{Winners: []string{"a", "b"}}, "Tie (a, b)"},
+	}
+	for _, tt := range tests {
+		if got := tt.outcome.String(); got != tt.want {
+			t.Errorf("%+v.String() = %q, want %q", tt.outcome, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package probes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// DefaultOllamaURL is the base URL of a local Ollama server.
+const DefaultOllamaURL = "http://localhost:11434"
+
+// DefaultMaxBatchSize caps the number of texts sent in one /api/embed or
+// /v1/embeddings request.
+const DefaultMaxBatchSize = 64
+
+type ollamaEmbedder struct {
+	endpoint
+	baseURL      string
+	model        string
+	name         string
+	keepAlive    string
+	maxBatchSize int
+	prefix       string
+	dimension    int
+	digest       string
+	// legacy is set once the server has shown it lacks /api/embed.
+	legacy bool
+}
+
+// NewOllamaEmbedder returns an Embedder backed by the Ollama server at
+// cfg.BaseURL, or DefaultOllamaURL when unset. Texts are sent in batches to
+// /api/embed, falling back to one /api/embeddings call per text on servers
+// that predate the batch endpoint.
+func NewOllamaEmbedder(cfg EmbedderConfig) Embedder {
+	baseURL := strings.TrimRight(cfg.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = DefaultOllamaURL
+	}
+	maxBatchSize := cfg.MaxBatchSize
+	if maxBatchSize <= 0 {
+		maxBatchSize = DefaultMaxBatchSize
+	}
+	return &ollamaEmbedder{
+		endpoint:     newEndpoint(cfg),
+		baseURL:      baseURL,
+		model:        cfg.Model,
+		name:         cfg.DisplayName(),
+		keepAlive:    cfg.KeepAlive,
+		maxBatchSize: maxBatchSize,
+		prefix:       cfg.Prefix,
+	}
+}
+
+func (e *ollamaEmbedder) Name() string {
+	return e.name
+}
+
+func (e *ollamaEmbedder) Model() string {
+	return e.model
+}
+
+func (e *ollamaEmbedder) Dimension() int {
+	return e.dimension
+}
+
+func (e *ollamaEmbedder) Identity(ctx context.Context) (ModelIdentity, error) {
+	if e.digest == "" {
+		digest, err := e.lookupDigest(ctx)
+		if err != nil {
+			return ModelIdentity{}, fmt.Errorf("error looking up digest for %s: %w", e.model, err)
+		}
+		e.digest = digest
+	}
+	return ModelIdentity{
+		Backend: BackendOllama,
+		BaseURL: e.baseURL,
+		Model:   e.model,
+		Digest:  e.digest,
+		Prefix:  e.prefix,
+	}, nil
+}
+
+// lookupDigest finds the model in the server's /api/tags listing. Names
+// without a tag match the implicit ":latest".
+func (e *ollamaEmbedder) lookupDigest(ctx context.Context) (string, error) {
+	var tags struct {
+		Models []struct {
+			Name   string `json:"name"`
+			Model  string `json:"model"`
+			Digest string `json:"digest"`
+		} `json:"models"`
+	}
+	if err := e.getJSON(ctx, e.baseURL+"/api/tags", &tags); err != nil {
+		return "", err
+	}
+
+	want := e.model
+	if !strings.Contains(want, ":") {
+		want += ":latest"
+	}
+	for _, m := range tags.Models {
+		if m.Name == want || m.Model == want {
+			return m.Digest, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s is not installed", ErrModelNotFound, e.model)
+}
+
+func (e *ollamaEmbedder) Embed(ctx context.Context, text string) ([]float64, error) {
+	embeddings, err := e.EmbedBatch(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+	return embeddings[0], nil
+}
+
+func (e *ollamaEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float64, error) {
+	texts = withPrefix(e.prefix, texts)
+	embeddings := make([][]float64, 0, len(texts))
+	for start := 0; start < len(texts); start += e.maxBatchSize {
+		end := min(start+e.maxBatchSize, len(texts))
+		batch, err := e.embedChunk(ctx, texts[start:end])
+		if err != nil {
+			return nil, err
+		}
+		embeddings = append(embeddings, batch...)
+	}
+	if len(embeddings) > 0 && len(embeddings[0]) > 0 {
+		e.dimension = len(embeddings[0])
+	}
+	return embeddings, nil
+}
+
+func (e *ollamaEmbedder) embedChunk(ctx context.Context, texts []string) ([][]float64, error) {
+	if !e.legacy {
+		embeddings, err := e.embedBatchEndpoint(ctx, texts)
+		if !isMissingEndpoint(err) {
+			return embeddings, err
+		}
+		e.legacy = true
+	}
+
+	embeddings := make([][]float64, 0, len(texts))
+	for i, text := range texts {
+		emb, err := e.embedLegacyEndpoint(ctx, text)
+		if err != nil {
+			return nil, fmt.Errorf("error embedding text %d: %w", i+1, err)
+		}
+		embeddings = append(embeddings, emb)
+	}
+	return embeddings, nil
+}
+
+func (e *ollamaEmbedder) embedBatchEndpoint(ctx context.Context, texts []string) ([][]float64, error) {
+	payload := struct {
+		Model     string   `json:"model"`
+		Input     []string `json:"input"`
+		KeepAlive string   `json:"keep_alive,omitempty"`
+	}{
+		Model:     e.model,
+		Input:     texts,
+		KeepAlive: e.keepAlive,
+	}
+
+	var result struct {
+		Embeddings [][]float64 `json:"embeddings"`
+	}
+	if err := e.postJSON(ctx, e.baseURL+"/api/embed", payload, &result); err != nil {
+		return nil, err
+	}
+	if len(result.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("%w: expected %d embeddings, got %d", ErrBadResponse, len(texts), len(result.Embeddings))
+	}
+	for i, emb := range result.Embeddings {
+		if len(emb) == 0 {
+			return nil, fmt.Errorf("%w for text %d from %s", ErrEmptyEmbedding, i+1, e.model)
+		}
+	}
+	return result.Embeddings, nil
+}
+
+func (e *ollamaEmbedder) embedLegacyEndpoint(ctx context.Context, text string) ([]float64, error) {
+	payload := struct {
+		Model     string `json:"model"`
+		Prompt    string `json:"prompt"`
+		KeepAlive string `json:"keep_alive,omitempty"`
+	}{
+		Model:     e.model,
+		Prompt:    text,
+		KeepAlive: e.keepAlive,
+	}
+
+	var result struct {
+		Embedding []float64 `json:"embedding"`
+	}
+	if err := e.postJSON(ctx, e.baseURL+"/api/embeddings", payload, &result); err != nil {
+		return nil, err
+	}
+	if len(result.Embedding) == 0 {
+		return nil, fmt.Errorf("%w from %s", ErrEmptyEmbedding, e.model)
+	}
+	return result.Embedding, nil
+}
+
+// isMissingEndpoint reports whether err is the plain 404 an old Ollama server
+// returns for an unknown route. A 404 for an unknown model must not trigger
+// the fallback.
+func isMissingEndpoint(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		return false
+	}
+	return !errors.Is(err, ErrModelNotFound)
+}
@@ -0,0 +1,164 @@
+package probes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func newTestOllamaEmbedder(t *testing.T, cfg EmbedderConfig) Embedder {
+	t.Helper()
+	if cfg.Model == "" {
+		cfg.Model = "test-model"
+	}
+	cfg.MaxRetries = -1
+	embedder, err := NewEmbedder(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return embedder
+}
+
+func TestOllamaEmbedBatch(t *testing.T) {
+	var batches [][]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/embed" {
+			http.NotFound(w, r)
+			return
+		}
+		var req struct {
+			Model     string   `json:"model"`
+			Input     []string `json:"input"`
+			KeepAlive string   `json:"keep_alive"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.KeepAlive != "10m" {
+			t.Errorf("keep_alive = %q, want 10m", req.KeepAlive)
+		}
+		batches = append(batches, req.Input)
+		var embeddings [][]float64
+		for _, text := range req.Input {
+			embeddings = append(embeddings, []float64{float64(len(text)), 1})
+		}
+		json.NewEncoder(w).Encode(map[string]any{"embeddings": embeddings})
+	}))
+	defer server.Close()
+
+	embedder := newTestOllamaEmbedder(t, EmbedderConfig{BaseURL: server.URL, MaxBatchSize: 2, KeepAlive: "10m", Prefix: "q: "})
+	embeddings, err := embedder.EmbedBatch(context.Background(), []string{"a", "bb", "ccc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]float64{{4, 1}, {5, 1}, {6, 1}}
+	if !reflect.DeepEqual(embeddings, want) {
+		t.Errorf("embeddings = %v, want %v", embeddings, want)
+	}
+	wantBatches := [][]string{{"q: a", "q: bb"}, {"q: ccc"}}
+	if !reflect.DeepEqual(batches, wantBatches) {
+		t.Errorf("batches = %v, want %v", batches, wantBatches)
+	}
+	if got := embedder.Dimension(); got != 2 {
+		t.Errorf("Dimension() = %d, want 2", got)
+	}
+}
+
+func TestOllamaLegacyFallback(t *testing.T) {
+	var batchCalls, legacyCalls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/embed":
+			batchCalls++
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte("404 page not found"))
+		case "/api/embeddings":
+			legacyCalls++
+			var req struct {
+				Prompt string `json:"prompt"`
+			}
+			json.NewDecoder(r.Body).Decode(&req)
+			json.NewEncoder(w).Encode(map[string]any{"embedding": []float64{float64(len(req.Prompt)), 2}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	embedder := newTestOllamaEmbedder(t, EmbedderConfig{BaseURL: server.URL})
+	ctx := context.Background()
+	embeddings, err := embedder.EmbedBatch(ctx, []string{"a", "bb"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := [][]float64{{1, 2}, {2, 2}}; !reflect.DeepEqual(embeddings, want) {
+		t.Errorf("embeddings = %v, want %v", embeddings, want)
+	}
+	if _, err := embedder.Embed(ctx, "ccc"); err != nil {
+		t.Fatal(err)
+	}
+	// The missing endpoint is only probed once.
+	if batchCalls != 1 || legacyCalls != 3 {
+		t.Errorf("got %d /api/embed and %d /api/embeddings calls, want 1 and 3", batchCalls, legacyCalls)
+	}
+}
+
+func TestOllamaErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   error
+	}{
+		{"unknown model", http.StatusNotFound, `{"error": "model \"x\" not found, try pulling it first"}`, ErrModelNotFound},
+		{"unavailable", http.StatusServiceUnavailable, `{"error": "server busy"}`, ErrServerOverloaded},
+		{"error with 200", http.StatusOK, `{"error": "input too long"}`, ErrBadResponse},
+		{"empty embedding", http.StatusOK, `{"embeddings": [[]]}`, ErrEmptyEmbedding},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var legacyCalls int
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/api/embeddings" {
+					legacyCalls++
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			embedder := newTestOllamaEmbedder(t, EmbedderConfig{BaseURL: server.URL})
+			if _, err := embedder.Embed(context.Background(), "text"); !errors.Is(err, tt.want) {
+				t.Errorf("error = %v, want %v", err, tt.want)
+			}
+			if legacyCalls != 0 {
+				t.Errorf("fell back to /api/embeddings on %s", tt.name)
+			}
+		})
+	}
+}
+
+func TestOllamaIdentity(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/tags" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"models": [{"name": "test-model:latest", "model": "test-model:latest", "digest": "abc123"}]}`))
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	id, err := newTestOllamaEmbedder(t, EmbedderConfig{BaseURL: server.URL}).Identity(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id.Digest != "abc123" || id.Backend != BackendOllama {
+		t.Errorf("identity = %+v, want ollama digest abc123", id)
+	}
+	if _, err := newTestOllamaEmbedder(t, EmbedderConfig{BaseURL: server.URL, Model: "other"}).Identity(ctx); !errors.Is(err, ErrModelNotFound) {
+		t.Errorf("error = %v, want %v", err, ErrModelNotFound)
+	}
+}
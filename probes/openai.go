@@ -0,0 +1,196 @@
+package probes
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// DefaultOpenAIURL is the base URL used for OpenAI-compatible backends when
+// none is configured.
+const DefaultOpenAIURL = "https://api.openai.com"
+
+type openAIEmbedder struct {
+	endpoint
+	url            string
+	model          string
+	name           string
+	dimensions     int
+	encodingFormat string
+	maxBatchSize   int
+	prefix         string
+	dimension      int
+	tokens         int
+}
+
+// NewOpenAIEmbedder returns an Embedder that speaks the OpenAI /v1/embeddings
+// protocol, as served by OpenAI, vLLM, llama.cpp, LocalAI and TEI.
+func NewOpenAIEmbedder(cfg EmbedderConfig) (Embedder, error) {
+	switch cfg.EncodingFormat {
+	case "", "float", "base64":
+	default:
+		return nil, fmt.Errorf("unsupported encoding format %q for model %s", cfg.EncodingFormat, cfg.Model)
+	}
+
+	baseURL := strings.TrimRight(cfg.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = DefaultOpenAIURL
+	}
+	url := baseURL + "/v1/embeddings"
+	if strings.HasSuffix(baseURL, "/v1") {
+		url = baseURL + "/embeddings"
+	}
+	maxBatchSize := cfg.MaxBatchSize
+	if maxBatchSize <= 0 {
+		maxBatchSize = DefaultMaxBatchSize
+	}
+
+	return &openAIEmbedder{
+		endpoint:       newEndpoint(cfg),
+		url:            url,
+		model:          cfg.Model,
+		name:           cfg.DisplayName(),
+		dimensions:     cfg.Dimensions,
+		encodingFormat: cfg.EncodingFormat,
+		maxBatchSize:   maxBatchSize,
+		prefix:         cfg.Prefix,
+	}, nil
+}
+
+func (e *openAIEmbedder) Name() string {
+	return e.name
+}
+
+func (e *openAIEmbedder) Model() string {
+	return e.model
+}
+
+func (e *openAIEmbedder) Dimension() int {
+	return e.dimension
+}
+
+// Identity has no digest: OpenAI-compatible servers do not report one, so
+// the requested dimensions are folded into the model name instead.
+func (e *openAIEmbedder) Identity(ctx context.Context) (ModelIdentity, error) {
+	model := e.model
+	if e.dimensions > 0 {
+		model = fmt.Sprintf("%s@%d", model, e.dimensions)
+	}
+	return ModelIdentity{
+		Backend: BackendOpenAI,
+		BaseURL: e.url,
+		Model:   model,
+		Prefix:  e.prefix,
+	}, nil
+}
+
+// TokensUsed returns the total tokens reported by the server so far.
+func (e *openAIEmbedder) TokensUsed() int {
+	return e.tokens
+}
+
+func (e *openAIEmbedder) Embed(ctx context.Context, text string) ([]float64, error) {
+	embeddings, err := e.EmbedBatch(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+	return embeddings[0], nil
+}
+
+// EmbedBatch sends the texts in requests of at most maxBatchSize inputs, as
+// servers cap the inputs per request (OpenAI at 2048).
+func (e *openAIEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float64, error) {
+	embeddings := make([][]float64, 0, len(texts))
+	for start := 0; start < len(texts); start += e.maxBatchSize {
+		end := min(start+e.maxBatchSize, len(texts))
+		batch, err := e.embedChunk(ctx, texts[start:end])
+		if err != nil {
+			return nil, err
+		}
+		embeddings = append(embeddings, batch...)
+	}
+	if len(embeddings) > 0 {
+		e.dimension = len(embeddings[0])
+	}
+	return embeddings, nil
+}
+
+func (e *openAIEmbedder) embedChunk(ctx context.Context, texts []string) ([][]float64, error) {
+	payload := struct {
+		Model          string   `json:"model"`
+		Input          []string `json:"input"`
+		Dimensions     int      `json:"dimensions,omitempty"`
+		EncodingFormat string   `json:"encoding_format,omitempty"`
+	}{
+		Model:          e.model,
+		Input:          withPrefix(e.prefix, texts),
+		Dimensions:     e.dimensions,
+		EncodingFormat: e.encodingFormat,
+	}
+	var result struct {
+		Data []struct {
+			Index     int             `json:"index"`
+			Embedding json.RawMessage `json:"embedding"`
+		} `json:"data"`
+		Usage struct {
+			PromptTokens int `json:"prompt_tokens"`
+			TotalTokens  int `json:"total_tokens"`
+		} `json:"usage"`
+	}
+	if err := e.postJSON(ctx, e.url, payload, &result); err != nil {
+		return nil, err
+	}
+	if len(result.Data) != len(texts) {
+		return nil, fmt.Errorf("%w: expected %d embeddings, got %d", ErrBadResponse, len(texts), len(result.Data))
+	}
+
+	embeddings := make([][]float64, len(texts))
+	for _, item := range result.Data {
+		if item.Index < 0 || item.Index >= len(texts) {
+			return nil, fmt.Errorf("%w: embedding index %d out of range", ErrBadResponse, item.Index)
+		}
+		emb, err := decodeOpenAIEmbedding(item.Embedding)
+		if err != nil {
+			return nil, fmt.Errorf("%w: error decoding embedding %d: %v", ErrBadResponse, item.Index, err)
+		}
+		embeddings[item.Index] = emb
+	}
+	for i, emb := range embeddings {
+		if len(emb) == 0 {
+			return nil, fmt.Errorf("%w for input %d from %s", ErrEmptyEmbedding, i+1, e.model)
+		}
+	}
+
+	e.tokens += result.Usage.TotalTokens
+	return embeddings, nil
+}
+
+// decodeOpenAIEmbedding accepts either a JSON float array or a base64 string
+// of little-endian float32 values, depending on the requested encoding_format.
+func decodeOpenAIEmbedding(raw json.RawMessage) ([]float64, error) {
+	var floats []float64
+	if err := json.Unmarshal(raw, &floats); err == nil {
+		return floats, nil
+	}
+
+	var encoded string
+	if err := json.Unmarshal(raw, &encoded); err != nil {
+		return nil, fmt.Errorf("embedding is neither a float array nor a base64 string")
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64 embedding: %v", err)
+	}
+	if len(data)%4 != 0 {
+		return nil, fmt.Errorf("base64 embedding length %d is not a multiple of 4", len(data))
+	}
+	floats = make([]float64, len(data)/4)
+	for i := range floats {
+		floats[i] = float64(math.Float32frombits(binary.LittleEndian.Uint32(data[i*4:])))
+	}
+	return floats, nil
+}
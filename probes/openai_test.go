@@ -0,0 +1,245 @@
+package probes
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// openAIRequest is the body the OpenAI backend sends.
+type openAIRequest struct {
+	Model          string   `json:"model"`
+	Input          []string `json:"input"`
+	Dimensions     int      `json:"dimensions"`
+	EncodingFormat string   `json:"encoding_format"`
+}
+
+// newOpenAIServer serves /v1/embeddings with handle and records every
+// request it receives.
+func newOpenAIServer(t *testing.T, handle func(w http.ResponseWriter, req openAIRequest)) (*httptest.Server, *[]openAIRequest) {
+	t.Helper()
+	var requests []openAIRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/embeddings" {
+			http.NotFound(w, r)
+			return
+		}
+		var req openAIRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+		requests = append(requests, req)
+		handle(w, req)
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func newTestOpenAIEmbedder(t *testing.T, cfg EmbedderConfig) Embedder {
+	t.Helper()
+	cfg.Backend = BackendOpenAI
+	if cfg.Model == "" {
+		cfg.Model = "test-model"
+	}
+	cfg.MaxRetries = -1
+	embedder, err := NewEmbedder(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return embedder
+}
+
+// testVector is the embedding the test servers return for input i.
+func testVector(i int) []float64 {
+	return []float64{float64(i + 1), 0.5, -0.25}
+}
+
+func base64Vector(v []float64) string {
+	data := make([]byte, 4*len(v))
+	for i, x := range v {
+		binary.LittleEndian.PutUint32(data[i*4:], math.Float32bits(float32(x)))
+	}
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+// answerOpenAI returns one embedding per input, in reverse index order, in
+// the requested encoding, with usage of 3 tokens per input.
+func answerOpenAI(w http.ResponseWriter, req openAIRequest) {
+	var data []map[string]any
+	for i := len(req.Input) - 1; i >= 0; i-- {
+		var embedding any = testVector(i)
+		if req.EncodingFormat == "base64" {
+			embedding = base64Vector(testVector(i))
+		}
+		data = append(data, map[string]any{"index": i, "embedding": embedding})
+	}
+	json.NewEncoder(w).Encode(map[string]any{
+		"data":  data,
+		"usage": map[string]int{"prompt_tokens": 3 * len(req.Input), "total_tokens": 3 * len(req.Input)},
+	})
+}
+
+func TestOpenAIEmbedBatch(t *testing.T) {
+	for _, format := range []string{"", "float", "base64"} {
+		t.Run("format="+format, func(t *testing.T) {
+			server, requests := newOpenAIServer(t, answerOpenAI)
+			embedder := newTestOpenAIEmbedder(t, EmbedderConfig{BaseURL: server.URL, EncodingFormat: format, Dimensions: 3})
+
+			texts := []string{"first", "second", "third"}
+			embeddings, err := embedder.EmbedBatch(context.Background(), texts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := range texts {
+				if !reflect.DeepEqual(embeddings[i], testVector(i)) {
+					t.Errorf("embedding %d = %v, want %v", i, embeddings[i], testVector(i))
+				}
+			}
+			if got := embedder.Dimension(); got != 3 {
+				t.Errorf("Dimension() = %d, want 3", got)
+			}
+			if got := TokensUsed(embedder); got != 9 {
+				t.Errorf("TokensUsed() = %d, want 9", got)
+			}
+
+			req := (*requests)[0]
+			if req.Dimensions != 3 {
+				t.Errorf("request dimensions = %d, want 3", req.Dimensions)
+			}
+			if req.EncodingFormat != format {
+				t.Errorf("request encoding_format = %q, want %q", req.EncodingFormat, format)
+			}
+			if !reflect.DeepEqual(req.Input, texts) {
+				t.Errorf("request input = %v, want %v", req.Input, texts)
+			}
+		})
+	}
+}
+
+func TestOpenAITokensAccumulate(t *testing.T) {
+	server, _ := newOpenAIServer(t, answerOpenAI)
+	embedder := newTestOpenAIEmbedder(t, EmbedderConfig{BaseURL: server.URL})
+	ctx := context.Background()
+	if _, err := embedder.Embed(ctx, "one"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := embedder.EmbedBatch(ctx, []string{"two", "three"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := TokensUsed(embedder); got != 9 {
+		t.Errorf("TokensUsed() = %d, want 9", got)
+	}
+}
+
+func TestOpenAIErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   error
+	}{
+		{"unknown model", http.StatusNotFound, `{"error": {"message": "The model 'x' does not exist"}}`, ErrModelNotFound},
+		{"unavailable", http.StatusServiceUnavailable, `{"error": {"message": "try again later"}}`, ErrServerOverloaded},
+		{"rate limited", http.StatusTooManyRequests, `rate limited`, ErrServerOverloaded},
+		{"bad request", http.StatusBadRequest, `{"error": {"message": "input too long"}}`, ErrBadResponse},
+		{"error with 200", http.StatusOK, `{"error": {"message": "input too long"}}`, ErrBadResponse},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, _ := newOpenAIServer(t, func(w http.ResponseWriter, req openAIRequest) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			})
+			embedder := newTestOpenAIEmbedder(t, EmbedderConfig{BaseURL: server.URL})
+
+			_, err := embedder.Embed(context.Background(), "text")
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("error = %v, want %v", err, tt.want)
+			}
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("error %v is not an *APIError", err)
+			}
+			wantStatus := tt.status
+			if wantStatus == http.StatusOK {
+				wantStatus = 0
+			}
+			if apiErr.StatusCode != wantStatus {
+				t.Errorf("StatusCode = %d, want %d", apiErr.StatusCode, wantStatus)
+			}
+		})
+	}
+}
+
+func TestOpenAIBadResponses(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want error
+	}{
+		{"too few embeddings", `{"data": [{"index": 0, "embedding": [1, 2]}]}`, ErrBadResponse},
+		{"index out of range", `{"data": [{"index": 0, "embedding": [1]}, {"index": 5, "embedding": [1]}]}`, ErrBadResponse},
+		{"empty embedding", `{"data": [{"index": 0, "embedding": []}, {"index": 1, "embedding": [1]}]}`, ErrEmptyEmbedding},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, _ := newOpenAIServer(t, func(w http.ResponseWriter, req openAIRequest) {
+				w.Write([]byte(tt.body))
+			})
+			embedder := newTestOpenAIEmbedder(t, EmbedderConfig{BaseURL: server.URL})
+			if _, err := embedder.EmbedBatch(context.Background(), []string{"a", "b"}); !errors.Is(err, tt.want) {
+				t.Errorf("error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenAIBatching(t *testing.T) {
+	server, requests := newOpenAIServer(t, answerOpenAI)
+	embedder := newTestOpenAIEmbedder(t, EmbedderConfig{BaseURL: server.URL, MaxBatchSize: 2, Prefix: "q: "})
+
+	embeddings, err := embedder.EmbedBatch(context.Background(), []string{"a", "b", "c", "d", "e"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sizes []int
+	for _, req := range *requests {
+		sizes = append(sizes, len(req.Input))
+	}
+	if !reflect.DeepEqual(sizes, []int{2, 2, 1}) {
+		t.Errorf("request sizes = %v, want [2 2 1]", sizes)
+	}
+	if got := (*requests)[2].Input; !reflect.DeepEqual(got, []string{"q: e"}) {
+		t.Errorf("last request input = %v, want [q: e]", got)
+	}
+	// Each request numbers its inputs from zero.
+	want := [][]float64{testVector(0), testVector(1), testVector(0), testVector(1), testVector(0)}
+	if !reflect.DeepEqual(embeddings, want) {
+		t.Errorf("embeddings = %v, want %v", embeddings, want)
+	}
+	if got := TokensUsed(embedder); got != 15 {
+		t.Errorf("TokensUsed() = %d, want 15", got)
+	}
+}
+
+func TestOpenAIEmptyBatch(t *testing.T) {
+	server, requests := newOpenAIServer(t, func(w http.ResponseWriter, req openAIRequest) {
+		w.Write([]byte(`{"data": []}`))
+	})
+	embedder := newTestOpenAIEmbedder(t, EmbedderConfig{BaseURL: server.URL})
+
+	embeddings, err := embedder.EmbedBatch(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(embeddings) != 0 || len(*requests) != 0 {
+		t.Errorf("got %d embeddings from %d requests, want none", len(embeddings), len(*requests))
+	}
+}
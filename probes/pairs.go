@@ -0,0 +1,274 @@
+package probes
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Pair is two texts to compare, with an optional gold similarity score and
+// optional language codes.
+type Pair struct {
+	ID    string   `json:"id,omitempty"`
+	TextA string   `json:"text_a"`
+	TextB string   `json:"text_b"`
+	Score *float64 `json:"score,omitempty"`
+	LangA string   `json:"lang_a,omitempty"`
+	LangB string   `json:"lang_b,omitempty"`
+}
+
+// PairTaskConfig declares a pair-similarity task in config.json.
+type PairTaskConfig struct {
+	Name string `json:"name"`
+	// File is a .tsv or .jsonl pair file.
+	File string `json:"file"`
+	// Metric is the metric's display name; defaults to "Mean Similarity".
+	Metric string `json:"metric,omitempty"`
+	// ShuffledControl also registers a control task that pairs every
+	// text_a with another pair's text_b.
+	ShuffledControl bool `json:"shuffled_control,omitempty"`
+}
+
+// LoadPairs reads pairs from a TSV or JSON Lines file, chosen by extension.
+//
+// TSV rows are text_a, text_b and optionally score, lang_a and lang_b. Blank
+// lines and lines starting with # are skipped, as is a header row whose first
+// column is "text_a". JSONL lines are objects with the same field names plus
+// an optional id. Pairs without an id are numbered pair-N.
+func LoadPairs(path string) ([]Pair, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading pair file %s: %v", path, err)
+	}
+
+	var pairs []Pair
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	jsonl := strings.EqualFold(filepath.Ext(path), ".jsonl")
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var pair Pair
+		if jsonl {
+			dec := json.NewDecoder(strings.NewReader(line))
+			dec.DisallowUnknownFields()
+			if err := dec.Decode(&pair); err != nil {
+				return nil, fmt.Errorf("%s:%d: %v", path, lineNum, err)
+			}
+		} else {
+			fields := strings.Split(line, "\t")
+			if fields[0] == "text_a" {
+				continue
+			}
+			if len(fields) < 2 || len(fields) > 5 {
+				return nil, fmt.Errorf("%s:%d: expected 2 to 5 tab-separated columns, got %d", path, lineNum, len(fields))
+			}
+			pair.TextA, pair.TextB = fields[0], fields[1]
+			if len(fields) > 2 && fields[2] != "" {
+				score, err := strconv.ParseFloat(fields[2], 64)
+				if err != nil {
+					return nil, fmt.Errorf("%s:%d: invalid score %q", path, lineNum, fields[2])
+				}
+				pair.Score = &score
+			}
+			if len(fields) > 3 {
+				pair.LangA = fields[3]
+			}
+			if len(fields) > 4 {
+				pair.LangB = fields[4]
+			}
+		}
+
+		if pair.TextA == "" || pair.TextB == "" {
+			return nil, fmt.Errorf("%s:%d: both texts are required", path, lineNum)
+		}
+		if pair.ID == "" {
+			pair.ID = fmt.Sprintf("pair-%d", len(pairs)+1)
+		}
+		pairs = append(pairs, pair)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading pair file %s: %v", path, err)
+	}
+	if len(pairs) == 0 {
+		return nil, fmt.Errorf("pair file %s has no pairs", path)
+	}
+	return pairs, nil
+}
+
+// RegisterPairTasks loads each configured pair file and registers a task
+// for it.
+func RegisterPairTasks(configs []PairTaskConfig) error {
+	for _, cfg := range configs {
+		if cfg.Name == "" {
+			return fmt.Errorf("pair task for %s has no name", cfg.File)
+		}
+		pairs, err := LoadPairs(cfg.File)
+		if err != nil {
+			return err
+		}
+		metric := cfg.Metric
+		if metric == "" {
+			metric = "Mean Similarity"
+		}
+		if err := registerUnique(NewPairTask(cfg.Name, metric, pairs)); err != nil {
+			return err
+		}
+		if cfg.ShuffledControl {
+			shuffled, err := ShufflePairs(pairs)
+			if err != nil {
+				return fmt.Errorf("pair task %s: %v", cfg.Name, err)
+			}
+			control := &pairTask{name: cfg.Name + " (Shuffled Control)", metric: metric, pairs: shuffled, control: true}
+			if err := registerUnique(control); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+const (
+	// shuffleSeed fixes the shuffled controls so reruns agree.
+	shuffleSeed = 1
+	// maxShuffleAttempts bounds the redraws when pairs share a text_b.
+	maxShuffleAttempts = 100
+)
+
+// ShufflePairs returns mismatched pairs for a negative control: text_a of
+// each pair is matched with text_b of another pair, chosen by a random
+// derangement, so no pair keeps its partner's text. Unlike a rotation, the
+// new partners do not follow the file order. The seed is fixed, so reruns
+// agree.
+func ShufflePairs(pairs []Pair) ([]Pair, error) {
+	if len(pairs) < 2 {
+		return nil, fmt.Errorf("a shuffled control needs at least two pairs")
+	}
+	rng := rand.New(rand.NewPCG(shuffleSeed, 0))
+	for attempt := 0; attempt < maxShuffleAttempts; attempt++ {
+		partner := derangement(rng, len(pairs))
+		shuffled := make([]Pair, len(pairs))
+		mismatched := true
+		for i, pair := range pairs {
+			other := pairs[partner[i]]
+			if other.TextB == pair.TextB {
+				// Duplicate texts can restore a partner; draw again.
+				mismatched = false
+				break
+			}
+			shuffled[i] = Pair{
+				ID:    "shuffled-" + pair.ID,
+				TextA: pair.TextA,
+				TextB: other.TextB,
+				LangA: pair.LangA,
+				LangB: other.LangB,
+			}
+		}
+		if mismatched {
+			return shuffled, nil
+		}
+	}
+	return nil, fmt.Errorf("could not shuffle the pairs so that every text_a gets a different text_b; too many pairs share a text_b")
+}
+
+// derangement returns a random permutation of [0, n) that moves every
+// index, drawn with Sattolo's algorithm as a single cycle.
+func derangement(rng *rand.Rand, n int) []int {
+	perm := make([]int, n)
+	for i := range perm {
+		perm[i] = i
+	}
+	for i := n - 1; i > 0; i-- {
+		j := rng.IntN(i)
+		perm[i], perm[j] = perm[j], perm[i]
+	}
+	return perm
+}
+
+// pairTask scores each model by the mean cosine similarity over its pairs.
+type pairTask struct {
+	name    string
+	metric  string
+	pairs   []Pair
+	control bool
+}
+
+// NewPairTask returns a task reporting mean pair similarity under the given
+// metric name.
+func NewPairTask(name, metric string, pairs []Pair) Task {
+	return &pairTask{name: name, metric: metric, pairs: pairs}
+}
+
+func (t *pairTask) Name() string {
+	return t.name
+}
+
+func (t *pairTask) Category() string {
+	return "Pair Similarity"
+}
+
+func (t *pairTask) Metric() MetricDescriptor {
+	return MetricDescriptor{Name: t.metric, Direction: HigherIsBetter, Tolerance: 1e-4, Min: -1, Max: 1, Control: t.control}
+}
+
+func (t *pairTask) ItemScoresAreSimilarities() bool {
+	return true
+}
+
+func (t *pairTask) MetricFromItems(items []ItemResult) float64 {
+	return meanScore(items)
+}
+
+func (t *pairTask) Run(ctx context.Context, embedders []Embedder) (map[string]TaskResult, error) {
+	results := make(map[string]TaskResult)
+
+	for _, embedder := range embedders {
+		model := embedder.Name()
+		fmt.Printf("Model: %s\n", model)
+
+		texts := make([]string, 0, 2*len(t.pairs))
+		for _, pair := range t.pairs {
+			texts = append(texts, pair.TextA, pair.TextB)
+		}
+		embeddings, err := embedder.EmbedBatch(ctx, texts)
+		if err != nil {
+			return nil, fmt.Errorf("error getting embeddings: %v", err)
+		}
+
+		var totalSimilarity float64
+		var items []ItemResult
+		for i, pair := range t.pairs {
+			sim, err := cosineSimilarity(embeddings[2*i], embeddings[2*i+1])
+			if err != nil {
+				return nil, fmt.Errorf("error computing similarity for %s: %v", pair.ID, err)
+			}
+
+			fmt.Printf("Cosine similarity for %s (%s vs. %s): %.4f\n", pair.ID, pair.TextA, pair.TextB, sim)
+			items = append(items, ItemResult{
+				ID:       pair.ID,
+				InputIDs: []string{pair.ID + "-a", pair.ID + "-b"},
+				Texts:    []string{pair.TextA, pair.TextB},
+				Score:    sim,
+			})
+			totalSimilarity += sim
+		}
+
+		avgSimilarity := totalSimilarity / float64(len(t.pairs))
+		fmt.Printf("Average similarity: %.4f\n", avgSimilarity)
+		results[model] = TaskResult{Metric: avgSimilarity, SubMetrics: similarityRangeSubMetrics(items), Items: items}
+	}
+
+	return results, nil
+}
@@ -0,0 +1,154 @@
+package probes
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestLoadPairs(t *testing.T) {
+	score := func(v float64) *float64 { return &v }
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    []Pair
+		err     string
+	}{
+		{
+			name:    "tsv",
+			file:    "pairs.tsv",
+			content: "text_a\ttext_b\tscore\n# comment\n\ncat\tdog\t0.5\r\nsun\tmoon\n",
+			want: []Pair{
+				{ID: "pair-1", TextA: "cat", TextB: "dog", Score: score(0.5)},
+				{ID: "pair-2", TextA: "sun", TextB: "moon"},
+			},
+		},
+		{
+			name:    "tsv languages without score",
+			file:    "pairs.tsv",
+			content: "hello\thallo\t\ten\tde\n",
+			want:    []Pair{{ID: "pair-1", TextA: "hello", TextB: "hallo", LangA: "en", LangB: "de"}},
+		},
+		{
+			name: "jsonl",
+			file: "pairs.JSONL",
+			content: `{"id": "x", "text_a": "cat", "text_b": "dog", "score": 4}` + "\n\n" +
+				`{"text_a": "sun", "text_b": "moon", "lang_a": "en", "lang_b": "en"}` + "\n",
+			want: []Pair{
+				{ID: "x", TextA: "cat", TextB: "dog", Score: score(4)},
+				{ID: "pair-2", TextA: "sun", TextB: "moon", LangA: "en", LangB: "en"},
+			},
+		},
+		{name: "tsv one column", file: "pairs.tsv", content: "cat\n", err: ":1: expected 2 to 5 tab-separated columns, got 1"},
+		{name: "tsv six columns", file: "pairs.tsv", content: "a\tb\t1\ten\ten\tx\n", err: "got 6"},
+		{name: "tsv bad score", file: "pairs.tsv", content: "a\tb\n# c\na\tb\thigh\n", err: `:3: invalid score "high"`},
+		{name: "tsv empty text", file: "pairs.tsv", content: "a\t\n", err: ":1: both texts are required"},
+		{name: "jsonl unknown field", file: "pairs.jsonl", content: `{"text_a": "a", "text_b": "b", "label": 1}`, err: `:1: json: unknown field "label"`},
+		{name: "jsonl missing text", file: "pairs.jsonl", content: `{"text_a": "a"}`, err: ":1: both texts are required"},
+		{name: "no pairs", file: "pairs.tsv", content: "text_a\ttext_b\n", err: "has no pairs"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			writeFile(t, path, tt.content)
+			pairs, err := LoadPairs(path)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("err = %v, want it to contain %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(pairs, tt.want) {
+				t.Errorf("pairs = %+v, want %+v", pairs, tt.want)
+			}
+		})
+	}
+}
+
+func TestShufflePairs(t *testing.T) {
+	tests := []struct {
+		name   string
+		textBs []string
+	}{
+		{"two pairs", []string{"b1", "b2"}},
+		{"many pairs", []string{"b1", "b2", "b3", "b4", "b5", "b6", "b7", "b8", "b9", "b10"}},
+		{"shared text", []string{"same", "same", "b3", "b4", "b5"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pairs := make([]Pair, len(tt.textBs))
+			for i, textB := range tt.textBs {
+				pairs[i] = Pair{ID: fmt.Sprint(i), TextA: fmt.Sprintf("a%d", i), TextB: textB}
+			}
+			shuffled, err := ShufflePairs(pairs)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for i, pair := range shuffled {
+				if pair.TextA != pairs[i].TextA {
+					t.Errorf("pair %d: text_a = %q, want %q", i, pair.TextA, pairs[i].TextA)
+				}
+				if pair.TextB == pairs[i].TextB {
+					t.Errorf("pair %d kept its partner's text %q", i, pair.TextB)
+				}
+				got = append(got, pair.TextB)
+			}
+			want := append([]string(nil), tt.textBs...)
+			sort.Strings(got)
+			sort.Strings(want)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("shuffled text_b = %v, want a permutation of %v", got, want)
+			}
+			again, _ := ShufflePairs(pairs)
+			if !reflect.DeepEqual(again, shuffled) {
+				t.Error("shuffle differs between calls")
+			}
+		})
+	}
+
+	// A rotation would give every pair the next one's text.
+	pairs := make([]Pair, 10)
+	for i := range pairs {
+		pairs[i] = Pair{TextA: fmt.Sprint(i), TextB: fmt.Sprint(i)}
+	}
+	shuffled, _ := ShufflePairs(pairs)
+	rotated := true
+	for i, pair := range shuffled {
+		if pair.TextB != pairs[(i+1)%len(pairs)].TextB {
+			rotated = false
+		}
+	}
+	if rotated {
+		t.Error("shuffle is a rotation by one")
+	}
+}
+
+func TestShufflePairsErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		textBs []string
+	}{
+		{"one pair", []string{"b"}},
+		{"all texts shared", []string{"same", "same", "same"}},
+		{"most texts shared", []string{"same", "same", "same", "other"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pairs := make([]Pair, len(tt.textBs))
+			for i, textB := range tt.textBs {
+				pairs[i] = Pair{TextA: fmt.Sprintf("a%d", i), TextB: textB}
+			}
+			if _, err := ShufflePairs(pairs); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
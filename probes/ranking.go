@@ -0,0 +1,178 @@
+package probes
+
+import (
+	"math"
+	"sort"
+)
+
+// ndcg returns the normalised discounted cumulative gain of ranking items by
+// descending score, with gain 2^grade - 1. Only the first k ranks count when
+// k > 0. It returns 0 when no item has a positive grade.
+func ndcg(scores, grades []float64, k int) float64 {
+	order := make([]int, len(scores))
+	for i := range order {
+		order[i] = i
+	}
+	// Ties in score are broken pessimistically, lowest grade first, so a
+	// model cannot gain from placing items at exactly the same similarity.
+	sort.SliceStable(order, func(a, b int) bool {
+		if scores[order[a]] != scores[order[b]] {
+			return scores[order[a]] > scores[order[b]]
+		}
+		return grades[order[a]] < grades[order[b]]
+	})
+	ideal := append([]float64(nil), grades...)
+	sort.Sort(sort.Reverse(sort.Float64Slice(ideal)))
+
+	ranked := make([]float64, len(order))
+	for i, idx := range order {
+		ranked[i] = grades[idx]
+	}
+	idcg := dcg(ideal, k)
+	if idcg == 0 {
+		return 0
+	}
+	return dcg(ranked, k) / idcg
+}
+
+func dcg(grades []float64, k int) float64 {
+	if k <= 0 || k > len(grades) {
+		k = len(grades)
+	}
+	var total float64
+	for i := 0; i < k; i++ {
+		total += (math.Pow(2, grades[i]) - 1) / math.Log2(float64(i)+2)
+	}
+	return total
+}
+
+// kendallTau returns Kendall's tau-b between x and y, which accounts for ties
+// in either variable. It returns 0 when either variable is constant.
+func kendallTau(x, y []float64) float64 {
+	var concordant, discordant, tiesX, tiesY float64
+	for i := 0; i < len(x); i++ {
+		for j := i + 1; j < len(x); j++ {
+			dx := x[i] - x[j]
+			dy := y[i] - y[j]
+			switch {
+			case dx == 0 && dy == 0:
+			case dx == 0:
+				tiesX++
+			case dy == 0:
+				tiesY++
+			case (dx > 0) == (dy > 0):
+				concordant++
+			default:
+				discordant++
+			}
+		}
+	}
+	denom := math.Sqrt((concordant + discordant + tiesX) * (concordant + discordant + tiesY))
+	if denom == 0 {
+		return 0
+	}
+	return (concordant - discordant) / denom
+}
+
+// pairwiseOrderingAccuracy returns the fraction of item pairs with different
+// grades whose scores are ordered the same way as their grades. Equal scores
+// count as half right. It returns 0 when all grades are equal.
+func pairwiseOrderingAccuracy(scores, grades []float64) float64 {
+	var correct, total float64
+	for i := 0; i < len(scores); i++ {
+		for j := i + 1; j < len(scores); j++ {
+			if grades[i] == grades[j] {
+				continue
+			}
+			total++
+			hi, lo := i, j
+			if grades[j] > grades[i] {
+				hi, lo = j, i
+			}
+			switch {
+			case scores[hi] > scores[lo]:
+				correct++
+			case scores[hi] == scores[lo]:
+				correct += 0.5
+			}
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return correct / total
+}
+
+// gradedSubMetrics reports how well item Scores order items by their Gold
+// grades. Items without a grade are ignored.
+func gradedSubMetrics(items []ItemResult) []SubMetric {
+	var scores, grades []float64
+	for _, item := range items {
+		if item.Gold == nil {
+			continue
+		}
+		scores = append(scores, item.Score)
+		grades = append(grades, *item.Gold)
+	}
+	return []SubMetric{
+		{"nDCG", ndcg(scores, grades, 0)},
+		{"Kendall Tau", kendallTau(scores, grades)},
+		{"Pairwise Ordering Accuracy", pairwiseOrderingAccuracy(scores, grades)},
+	}
+}
+
+// pearson returns the Pearson correlation of x and y, or 0 when either is
+// constant.
+func pearson(x, y []float64) float64 {
+	n := float64(len(x))
+	if n == 0 {
+		return 0
+	}
+	var meanX, meanY float64
+	for i := range x {
+		meanX += x[i]
+		meanY += y[i]
+	}
+	meanX /= n
+	meanY /= n
+	var cov, varX, varY float64
+	for i := range x {
+		dx, dy := x[i]-meanX, y[i]-meanY
+		cov += dx * dy
+		varX += dx * dx
+		varY += dy * dy
+	}
+	if varX == 0 || varY == 0 {
+		return 0
+	}
+	return cov / math.Sqrt(varX*varY)
+}
+
+// spearman returns the Spearman rank correlation of x and y: the Pearson
+// correlation of their ranks, with tied values given their average rank.
+func spearman(x, y []float64) float64 {
+	return pearson(ranks(x), ranks(y))
+}
+
+// ranks returns the 1-based rank of each value, averaging ranks over ties.
+func ranks(values []float64) []float64 {
+	order := make([]int, len(values))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return values[order[a]] < values[order[b]] })
+	r := make([]float64, len(values))
+	for i := 0; i < len(order); {
+		j := i
+		for j < len(order) && values[order[j]] == values[order[i]] {
+			j++
+		}
+		// Positions i..j-1 share the mean of ranks i+1..j.
+		avg := float64(i+j+1) / 2
+		for k := i; k < j; k++ {
+			r[order[k]] = avg
+		}
+		i = j
+	}
+	return r
+}
@@ -0,0 +1,81 @@
+package probes
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNDCG(t *testing.T) {
+	// Grades 0, 1 and 2 in the worst order: gains 0, 1 and 3.
+	reversed := (1/math.Log2(3) + 3.0/2) / (3 + 1/math.Log2(3))
+	tests := []struct {
+		name   string
+		scores []float64
+		grades []float64
+		k      int
+		want   float64
+	}{
+		{"perfect", []float64{0.9, 0.5, 0.1}, []float64{2, 1, 0}, 0, 1},
+		{"perfect at k", []float64{0.9, 0.5, 0.1}, []float64{2, 1, 0}, 2, 1},
+		{"reversed", []float64{0.1, 0.5, 0.9}, []float64{2, 1, 0}, 0, reversed},
+		{"reversed at 1", []float64{0.1, 0.5, 0.9}, []float64{2, 1, 0}, 1, 0},
+		{"k beyond length", []float64{0.1, 0.5, 0.9}, []float64{2, 1, 0}, 10, reversed},
+		{"tied scores rank lowest grade first", []float64{0.5, 0.5, 0.5}, []float64{2, 1, 0}, 0, reversed},
+		{"binary, relevant second", []float64{0.9, 0.8, 0.7}, []float64{0, 1, 0}, 0, 1 / math.Log2(3)},
+		{"no relevant items", []float64{0.9, 0.1}, []float64{0, 0}, 0, 0},
+		{"empty", nil, nil, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ndcg(tt.scores, tt.grades, tt.k); math.Abs(got-tt.want) > 1e-12 {
+				t.Errorf("ndcg = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKendallTau(t *testing.T) {
+	tests := []struct {
+		name string
+		x, y []float64
+		want float64
+	}{
+		{"identical", []float64{1, 2, 3, 4}, []float64{1, 2, 3, 4}, 1},
+		{"reversed", []float64{1, 2, 3, 4}, []float64{4, 3, 2, 1}, -1},
+		{"one swap", []float64{1, 2, 3}, []float64{1, 3, 2}, 1.0 / 3},
+		// Five concordant pairs and one tied in x only: 5/sqrt(6*5).
+		{"tie in x", []float64{1, 2, 2, 3}, []float64{1, 2, 3, 4}, 5 / math.Sqrt(30)},
+		{"constant", []float64{1, 1, 1}, []float64{1, 2, 3}, 0},
+		{"empty", nil, nil, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := kendallTau(tt.x, tt.y); math.Abs(got-tt.want) > 1e-12 {
+				t.Errorf("kendallTau = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPairwiseOrderingAccuracy(t *testing.T) {
+	tests := []struct {
+		name   string
+		scores []float64
+		grades []float64
+		want   float64
+	}{
+		{"perfect", []float64{0.9, 0.5, 0.1}, []float64{2, 1, 0}, 1},
+		{"reversed", []float64{0.1, 0.5, 0.9}, []float64{2, 1, 0}, 0},
+		{"one pair wrong", []float64{0.9, 0.1, 0.5}, []float64{2, 1, 0}, 2.0 / 3},
+		{"tied scores count half", []float64{0.5, 0.5}, []float64{1, 0}, 0.5},
+		{"equal grades ignored", []float64{0.6, 0.9, 0.5}, []float64{1, 1, 0}, 1},
+		{"all grades equal", []float64{0.1, 0.9}, []float64{1, 1}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pairwiseOrderingAccuracy(tt.scores, tt.grades); math.Abs(got-tt.want) > 1e-12 {
+				t.Errorf("pairwiseOrderingAccuracy = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
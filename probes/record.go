@@ -0,0 +1,202 @@
+package probes
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"time"
+)
+
+// RunRecord is a saved run: every selected task's results and outcome, in
+// task order. "run --output" writes it and "compare" and "report" read it.
+type RunRecord struct {
+	Started  time.Time     `json:"started"`
+	Duration time.Duration `json:"duration_ns"`
+	// Models lists every embedder's display name in config order, including
+	// baselines.
+	Models []string `json:"models"`
+	// Baselines lists the entries of Models that are baselines.
+	Baselines []string `json:"baselines,omitempty"`
+	// Identities maps each model that could be identified to its identity.
+	Identities map[string]ModelIdentity `json:"identities,omitempty"`
+	// Config is the config the run used, without secrets, and ConfigHash
+	// its ConfigHash.
+	Config     json.RawMessage `json:"config,omitempty"`
+	ConfigHash string          `json:"config_hash,omitempty"`
+	// ProbesCommit is the git commit of embedding-probes that made the run,
+	// with a "+dirty" suffix for uncommitted changes, or empty if unknown.
+	ProbesCommit string       `json:"probes_commit,omitempty"`
+	Tasks        []TaskRecord `json:"tasks"`
+}
+
+// TaskRecord is one task's entry in a RunRecord.
+type TaskRecord struct {
+	// Number is the task's position in the registry, which stays the same
+	// when only some tasks are run.
+	Number int              `json:"number"`
+	Name   string           `json:"name"`
+	Metric MetricDescriptor `json:"metric"`
+	// Category groups similar tasks in reports; see TaskCategory.
+	Category string `json:"category,omitempty"`
+	// SimilarityScores is set when item scores are cosine similarities.
+	SimilarityScores bool                  `json:"similarity_scores,omitempty"`
+	Cancelled        bool                  `json:"cancelled,omitempty"`
+	Results          map[string]TaskResult `json:"results,omitempty"`
+	Outcome          Outcome               `json:"outcome"`
+}
+
+// NewTaskRecord returns the record of task, registered as number, with no
+// results yet.
+func NewTaskRecord(number int, task Task) TaskRecord {
+	record := TaskRecord{Number: number, Name: task.Name(), Metric: task.Metric(), Category: TaskCategory(task)}
+	if scorer, ok := task.(SimilarityScorer); ok {
+		record.SimilarityScores = scorer.ItemScoresAreSimilarities()
+	}
+	return record
+}
+
+// Categorized is implemented by tasks that belong to a report category such
+// as "Retrieval".
+type Categorized interface {
+	Category() string
+}
+
+// TaskCategory returns task's category, or "Other" for tasks without one.
+func TaskCategory(task Task) string {
+	if c, ok := task.(Categorized); ok {
+		return c.Category()
+	}
+	return "Other"
+}
+
+// IsBaseline reports whether the named model is a baseline.
+func (r RunRecord) IsBaseline(model string) bool {
+	for _, name := range r.Baselines {
+		if name == model {
+			return true
+		}
+	}
+	return false
+}
+
+// ModelNames returns Models without the baselines.
+func (r RunRecord) ModelNames() []string {
+	var names []string
+	for _, name := range r.Models {
+		if !r.IsBaseline(name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Wins counts each model's outright task wins, and how many of them were
+// significant. Ties count for nobody.
+func (r RunRecord) Wins() (wins, significant map[string]int) {
+	wins = make(map[string]int)
+	significant = make(map[string]int)
+	for _, task := range r.Tasks {
+		if winner := task.Outcome.Winner(); winner != "" {
+			wins[winner]++
+			if task.Outcome.Significant() {
+				significant[winner]++
+			}
+		}
+	}
+	return wins, significant
+}
+
+// Task returns the task with the given name.
+func (r RunRecord) Task(name string) (TaskRecord, bool) {
+	for _, task := range r.Tasks {
+		if task.Name == name {
+			return task, true
+		}
+	}
+	return TaskRecord{}, false
+}
+
+// SaveRun writes record to path in the given export format. Only
+// FormatJSON can be read back by LoadRun.
+func SaveRun(path string, record RunRecord, format string) error {
+	var buf bytes.Buffer
+	if err := ExportRun(&buf, record, format); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("error writing run: %v", err)
+	}
+	return nil
+}
+
+// LoadRun reads a run saved by SaveRun.
+func LoadRun(path string) (RunRecord, error) {
+	var record RunRecord
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return record, fmt.Errorf("error reading run: %v", err)
+	}
+	if err := json.Unmarshal(data, &record); err != nil {
+		return record, fmt.Errorf("error parsing run %s: %v", path, err)
+	}
+	return record, nil
+}
+
+func (d Direction) MarshalText() ([]byte, error) {
+	if d == LowerIsBetter {
+		return []byte("lower_is_better"), nil
+	}
+	return []byte("higher_is_better"), nil
+}
+
+func (d *Direction) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "higher_is_better":
+		*d = HigherIsBetter
+	case "lower_is_better":
+		*d = LowerIsBetter
+	default:
+		return fmt.Errorf("unknown metric direction %q", text)
+	}
+	return nil
+}
+
+// metricDescriptorJSON is the saved form of a MetricDescriptor. JSON has no
+// infinities, so open ends of the range are omitted.
+type metricDescriptorJSON struct {
+	Name      string    `json:"name"`
+	Direction Direction `json:"direction"`
+	Tolerance float64   `json:"tolerance,omitempty"`
+	Min       *float64  `json:"min,omitempty"`
+	Max       *float64  `json:"max,omitempty"`
+	Control   bool      `json:"control,omitempty"`
+}
+
+func (m MetricDescriptor) MarshalJSON() ([]byte, error) {
+	out := metricDescriptorJSON{Name: m.Name, Direction: m.Direction, Tolerance: m.Tolerance, Control: m.Control}
+	if !math.IsInf(m.Min, 0) {
+		out.Min = &m.Min
+	}
+	if !math.IsInf(m.Max, 0) {
+		out.Max = &m.Max
+	}
+	return json.Marshal(out)
+}
+
+func (m *MetricDescriptor) UnmarshalJSON(data []byte) error {
+	var in metricDescriptorJSON
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	*m = MetricDescriptor{Name: in.Name, Direction: in.Direction, Tolerance: in.Tolerance,
+		Min: math.Inf(-1), Max: math.Inf(1), Control: in.Control}
+	if in.Min != nil {
+		m.Min = *in.Min
+	}
+	if in.Max != nil {
+		m.Max = *in.Max
+	}
+	return nil
+}
@@ -0,0 +1,372 @@
+package probes
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// DefaultRetrievalK is the cut-off for recall@k, MRR@k and nDCG@k when none
+// is configured.
+const DefaultRetrievalK = 10
+
+// RetrievalTaskConfig declares a retrieval benchmark in config.json, laid
+// out like a BEIR dataset.
+type RetrievalTaskConfig struct {
+	Name string `json:"name"`
+	// Dir holds corpus.jsonl, queries.jsonl and qrels/<split>.tsv.
+	Dir string `json:"dir,omitempty"`
+	// Split names the qrels file; defaults to "test".
+	Split string `json:"split,omitempty"`
+	// Corpus, Queries and Qrels override the paths derived from Dir.
+	Corpus  string `json:"corpus,omitempty"`
+	Queries string `json:"queries,omitempty"`
+	Qrels   string `json:"qrels,omitempty"`
+	// K defaults to DefaultRetrievalK.
+	K int `json:"k,omitempty"`
+}
+
+func (c RetrievalTaskConfig) paths() (corpus, queries, qrels string) {
+	split := c.Split
+	if split == "" {
+		split = "test"
+	}
+	corpus, queries, qrels = c.Corpus, c.Queries, c.Qrels
+	if corpus == "" {
+		corpus = filepath.Join(c.Dir, "corpus.jsonl")
+	}
+	if queries == "" {
+		queries = filepath.Join(c.Dir, "queries.jsonl")
+	}
+	if qrels == "" {
+		qrels = filepath.Join(c.Dir, "qrels", split+".tsv")
+	}
+	return corpus, queries, qrels
+}
+
+// RetrievalDocument is a corpus entry or a query. Corpus documents are
+// embedded as title and text joined by a space, as BEIR does.
+type RetrievalDocument struct {
+	ID    string `json:"_id"`
+	Title string `json:"title,omitempty"`
+	Text  string `json:"text"`
+}
+
+func (d RetrievalDocument) content() string {
+	if d.Title == "" {
+		return d.Text
+	}
+	return d.Title + " " + d.Text
+}
+
+// RetrievalDataset is a corpus, queries and graded relevance judgements.
+// Qrels maps a query id to document ids and their grades; grades above zero
+// are relevant.
+type RetrievalDataset struct {
+	Corpus  []RetrievalDocument
+	Queries []RetrievalDocument
+	Qrels   map[string]map[string]int
+}
+
+// LoadRetrievalDataset reads a BEIR-style dataset. Corpus and query files are
+// JSON Lines with "_id" and "text" fields, plus "title" for documents; other
+// fields such as "metadata" are ignored. The qrels file is tab-separated
+// query-id, corpus-id and score, with an optional header row. Queries with
+// no relevant documents are dropped, as BEIR's evaluation does.
+func LoadRetrievalDataset(corpusPath, queriesPath, qrelsPath string) (RetrievalDataset, error) {
+	var dataset RetrievalDataset
+	var err error
+	if dataset.Corpus, err = loadRetrievalDocuments(corpusPath); err != nil {
+		return dataset, err
+	}
+	queries, err := loadRetrievalDocuments(queriesPath)
+	if err != nil {
+		return dataset, err
+	}
+	if dataset.Qrels, err = loadQrels(qrelsPath); err != nil {
+		return dataset, err
+	}
+
+	docIDs := make(map[string]bool, len(dataset.Corpus))
+	for _, doc := range dataset.Corpus {
+		docIDs[doc.ID] = true
+	}
+	for queryID, judged := range dataset.Qrels {
+		for docID := range judged {
+			if !docIDs[docID] {
+				return dataset, fmt.Errorf("%s: query %s judges unknown document %s", qrelsPath, queryID, docID)
+			}
+		}
+	}
+	for _, query := range queries {
+		for _, grade := range dataset.Qrels[query.ID] {
+			if grade > 0 {
+				dataset.Queries = append(dataset.Queries, query)
+				break
+			}
+		}
+	}
+	if len(dataset.Queries) == 0 {
+		return dataset, fmt.Errorf("%s: no query has a relevant document", qrelsPath)
+	}
+	return dataset, nil
+}
+
+func loadRetrievalDocuments(path string) ([]RetrievalDocument, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %v", path, err)
+	}
+	defer file.Close()
+
+	var docs []RetrievalDocument
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var doc RetrievalDocument
+		if err := json.Unmarshal([]byte(line), &doc); err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", path, lineNum, err)
+		}
+		if doc.ID == "" {
+			return nil, fmt.Errorf("%s:%d: missing _id", path, lineNum)
+		}
+		if doc.content() == "" {
+			return nil, fmt.Errorf("%s:%d: %s has no text", path, lineNum, doc.ID)
+		}
+		if seen[doc.ID] {
+			return nil, fmt.Errorf("%s:%d: duplicate _id %s", path, lineNum, doc.ID)
+		}
+		seen[doc.ID] = true
+		docs = append(docs, doc)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading %s: %v", path, err)
+	}
+	if len(docs) == 0 {
+		return nil, fmt.Errorf("%s is empty", path)
+	}
+	return docs, nil
+}
+
+func loadQrels(path string) (map[string]map[string]int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %v", path, err)
+	}
+	defer file.Close()
+
+	qrels := make(map[string]map[string]int)
+	scanner := bufio.NewScanner(file)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		fields := strings.Split(line, "\t")
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("%s:%d: expected query-id, corpus-id and score, got %d columns", path, lineNum, len(fields))
+		}
+		grade, err := strconv.Atoi(fields[2])
+		if err != nil {
+			if lineNum == 1 {
+				continue // header
+			}
+			return nil, fmt.Errorf("%s:%d: invalid score %q", path, lineNum, fields[2])
+		}
+		if qrels[fields[0]] == nil {
+			qrels[fields[0]] = make(map[string]int)
+		}
+		qrels[fields[0]][fields[1]] = grade
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading %s: %v", path, err)
+	}
+	return qrels, nil
+}
+
+// RegisterRetrievalTasks loads each configured retrieval dataset and
+// registers a task for it.
+func RegisterRetrievalTasks(configs []RetrievalTaskConfig) error {
+	for _, cfg := range configs {
+		if cfg.Name == "" {
+			return fmt.Errorf("retrieval task for %s has no name", cfg.Dir)
+		}
+		dataset, err := LoadRetrievalDataset(cfg.paths())
+		if err != nil {
+			return err
+		}
+		if err := registerUnique(NewRetrievalTask(cfg.Name, dataset, cfg.K)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// retrievalTask ranks the whole corpus for every query by exact cosine
+// similarity and scores the rankings against the qrels.
+type retrievalTask struct {
+	name    string
+	dataset RetrievalDataset
+	k       int
+}
+
+// NewRetrievalTask returns a task reporting nDCG@k, with recall@k, MRR@k
+// and MAP as sub-metrics.
+func NewRetrievalTask(name string, dataset RetrievalDataset, k int) Task {
+	if k <= 0 {
+		k = DefaultRetrievalK
+	}
+	return &retrievalTask{name: name, dataset: dataset, k: k}
+}
+
+func (t *retrievalTask) Name() string {
+	return t.name
+}
+
+func (t *retrievalTask) Category() string {
+	return "Retrieval"
+}
+
+func (t *retrievalTask) Metric() MetricDescriptor {
+	return MetricDescriptor{Name: fmt.Sprintf("nDCG@%d", t.k), Direction: HigherIsBetter, Tolerance: 1e-4, Min: 0, Max: 1}
+}
+
+// MetricFromItems averages the per-query nDCG@k held in item scores.
+func (t *retrievalTask) MetricFromItems(items []ItemResult) float64 {
+	return meanScore(items)
+}
+
+func (t *retrievalTask) Run(ctx context.Context, embedders []Embedder) (map[string]TaskResult, error) {
+	corpus := make([]string, len(t.dataset.Corpus))
+	for i, doc := range t.dataset.Corpus {
+		corpus[i] = doc.content()
+	}
+	queries := make([]string, len(t.dataset.Queries))
+	for i, query := range t.dataset.Queries {
+		queries[i] = query.Text
+	}
+
+	results := make(map[string]TaskResult)
+
+	for _, embedder := range embedders {
+		model := embedder.Name()
+		fmt.Printf("Model: %s\n", model)
+
+		docEmbeddings, err := embedder.EmbedBatch(ctx, corpus)
+		if err != nil {
+			return nil, fmt.Errorf("error embedding corpus: %v", err)
+		}
+		queryEmbeddings, err := embedder.EmbedBatch(ctx, queries)
+		if err != nil {
+			return nil, fmt.Errorf("error embedding queries: %v", err)
+		}
+		docVectors, err := normalizeAll(docEmbeddings)
+		if err != nil {
+			return nil, fmt.Errorf("error embedding corpus: %v", err)
+		}
+		queryVectors, err := normalizeAll(queryEmbeddings)
+		if err != nil {
+			return nil, fmt.Errorf("error embedding queries: %v", err)
+		}
+
+		var sumNDCG, sumRecall, sumMRR, sumAP float64
+		var items []ItemResult
+		for qi, query := range t.dataset.Queries {
+			if len(queryVectors[qi]) != len(docVectors[0]) {
+				return nil, fmt.Errorf("query %s: embedding dimensions do not match: %d vs %d", query.ID, len(queryVectors[qi]), len(docVectors[0]))
+			}
+			scores := make([]float64, len(docVectors))
+			grades := make([]float64, len(docVectors))
+			judged := t.dataset.Qrels[query.ID]
+			for di, doc := range t.dataset.Corpus {
+				scores[di] = dot(queryVectors[qi], docVectors[di])
+				if grade := judged[doc.ID]; grade > 0 {
+					grades[di] = float64(grade)
+				}
+			}
+			ranked := rankCandidates(len(scores), nil, func(i int) float64 { return scores[i] })
+
+			queryNDCG := ndcg(scores, grades, t.k)
+			recall, mrr, ap := rankingScores(ranked, grades, t.k)
+			sumNDCG += queryNDCG
+			sumRecall += recall
+			sumMRR += mrr
+			sumAP += ap
+
+			var relevantIDs []string
+			for di, doc := range t.dataset.Corpus {
+				if grades[di] > 0 {
+					relevantIDs = append(relevantIDs, doc.ID)
+				}
+			}
+			top := t.dataset.Corpus[ranked[0]]
+			fmt.Printf("Query %s: nDCG@%d=%.4f, top hit %s (%.4f)\n", query.ID, t.k, queryNDCG, top.ID, scores[ranked[0]])
+			items = append(items, ItemResult{
+				ID:        query.ID,
+				InputIDs:  []string{query.ID, top.ID},
+				Texts:     []string{query.Text, top.content()},
+				Score:     queryNDCG,
+				Predicted: top.ID,
+				Actual:    strings.Join(relevantIDs, ","),
+			})
+		}
+
+		n := float64(len(t.dataset.Queries))
+		fmt.Printf("nDCG@%d: %.4f, Recall@%d: %.4f, MRR@%d: %.4f, MAP: %.4f\n",
+			t.k, sumNDCG/n, t.k, sumRecall/n, t.k, sumMRR/n, sumAP/n)
+		results[model] = TaskResult{
+			Metric: sumNDCG / n,
+			SubMetrics: []SubMetric{
+				{fmt.Sprintf("Recall@%d", t.k), sumRecall / n},
+				{fmt.Sprintf("MRR@%d", t.k), sumMRR / n},
+				{"MAP", sumAP / n},
+			},
+			Items: items,
+		}
+	}
+
+	return results, nil
+}
+
+// rankingScores returns recall@k, reciprocal rank within k and average
+// precision over the full ranking, treating positive grades as relevant.
+func rankingScores(ranked []int, grades []float64, k int) (recall, rr, ap float64) {
+	relevant := 0
+	for _, grade := range grades {
+		if grade > 0 {
+			relevant++
+		}
+	}
+	if relevant == 0 {
+		return 0, 0, 0
+	}
+	hits := 0
+	for rank, idx := range ranked {
+		if grades[idx] <= 0 {
+			continue
+		}
+		hits++
+		if rank < k {
+			if hits == 1 {
+				rr = 1 / float64(rank+1)
+			}
+			recall++
+		}
+		ap += float64(hits) / float64(rank+1)
+	}
+	return recall / float64(relevant), rr, ap / float64(relevant)
+}
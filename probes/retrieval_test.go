@@ -0,0 +1,154 @@
+package probes
+
+import (
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadQrels(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    map[string]map[string]int
+		err     string
+	}{
+		{
+			name:    "header and grades",
+			content: "query-id\tcorpus-id\tscore\nq1\td1\t2\nq1\td2\t0\n\nq2\td1\t1\n",
+			want:    map[string]map[string]int{"q1": {"d1": 2, "d2": 0}, "q2": {"d1": 1}},
+		},
+		{
+			name:    "no header, CRLF",
+			content: "q1\td1\t1\r\nq1\td3\t1\r\n",
+			want:    map[string]map[string]int{"q1": {"d1": 1, "d3": 1}},
+		},
+		{name: "empty", content: "", want: map[string]map[string]int{}},
+		{name: "bad score after header", content: "q1\td1\t1\nq1\td2\thigh\n", err: `:2: invalid score "high"`},
+		{name: "wrong columns", content: "q1\td1\n", err: ":1: expected query-id, corpus-id and score, got 2 columns"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "test.tsv")
+			writeFile(t, path, tt.content)
+			qrels, err := loadQrels(path)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("err = %v, want it to contain %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(qrels, tt.want) {
+				t.Errorf("qrels = %v, want %v", qrels, tt.want)
+			}
+		})
+	}
+}
+
+func TestRankingScores(t *testing.T) {
+	tests := []struct {
+		name           string
+		ranked         []int
+		grades         []float64
+		k              int
+		recall, rr, ap float64
+	}{
+		// Relevant at ranks 1 and 3: precision 1/1 and 2/3.
+		{"two relevant", []int{0, 1, 2, 3}, []float64{1, 0, 1, 0}, 10, 1, 1, (1 + 2.0/3) / 2},
+		{"two relevant at 1", []int{0, 1, 2, 3}, []float64{1, 0, 1, 0}, 1, 0.5, 1, (1 + 2.0/3) / 2},
+		{"relevant second at 1", []int{1, 0, 2}, []float64{1, 0, 0}, 1, 0, 0, 0.5},
+		{"relevant second at 2", []int{1, 0, 2}, []float64{1, 0, 0}, 2, 1, 0.5, 0.5},
+		{"grades above one count once", []int{2, 1, 0}, []float64{2, 0, 1}, 3, 1, 1, (1 + 2.0/3) / 2},
+		{"no relevant", []int{0, 1}, []float64{0, 0}, 2, 0, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recall, rr, ap := rankingScores(tt.ranked, tt.grades, tt.k)
+			if math.Abs(recall-tt.recall) > 1e-12 || math.Abs(rr-tt.rr) > 1e-12 || math.Abs(ap-tt.ap) > 1e-12 {
+				t.Errorf("rankingScores = (%v, %v, %v), want (%v, %v, %v)", recall, rr, ap, tt.recall, tt.rr, tt.ap)
+			}
+		})
+	}
+}
+
+func TestLoadRetrievalDataset(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "corpus.jsonl"),
+		`{"_id": "d1", "title": "Paris", "text": "capital of france"}`+"\n"+
+			`{"_id": "d2", "text": "rivers of europe", "metadata": {}}`+"\n")
+	writeFile(t, filepath.Join(dir, "queries.jsonl"),
+		`{"_id": "q1", "text": "paris capital of france"}`+"\n"+
+			`{"_id": "q2", "text": "unjudged"}`+"\n"+
+			`{"_id": "q3", "text": "only irrelevant"}`+"\n")
+	writeFile(t, filepath.Join(dir, "qrels", "test.tsv"), "query-id\tcorpus-id\tscore\nq1\td1\t1\nq3\td2\t0\n")
+
+	dataset, err := LoadRetrievalDataset(RetrievalTaskConfig{Dir: dir}.paths())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dataset.Queries) != 1 || dataset.Queries[0].ID != "q1" {
+		t.Errorf("queries = %+v, want only q1", dataset.Queries)
+	}
+	if got := dataset.Corpus[0].content(); got != "Paris capital of france" {
+		t.Errorf("content = %q, want title and text", got)
+	}
+
+	writeFile(t, filepath.Join(dir, "qrels", "dev.tsv"), "q1\td9\t1\n")
+	if _, err := LoadRetrievalDataset(RetrievalTaskConfig{Dir: dir, Split: "dev"}.paths()); err == nil || !strings.Contains(err.Error(), "unknown document d9") {
+		t.Errorf("err = %v, want unknown document", err)
+	}
+}
+
+func TestRetrievalTaskExactMatch(t *testing.T) {
+	// Each query repeats its relevant document's text, so it has cosine
+	// similarity 1 and ranks first for any embedder.
+	dataset := RetrievalDataset{
+		Corpus: []RetrievalDocument{
+			{ID: "d1", Text: "the cat sat on the mat"},
+			{ID: "d2", Text: "stock markets fell sharply"},
+			{ID: "d3", Text: "a recipe for lemon cake"},
+		},
+		Queries: []RetrievalDocument{
+			{ID: "q1", Text: "stock markets fell sharply"},
+			{ID: "q2", Text: "a recipe for lemon cake"},
+		},
+		Qrels: map[string]map[string]int{"q1": {"d2": 1}, "q2": {"d3": 2}},
+	}
+	task := NewRetrievalTask("exact", dataset, 2)
+	if name := task.Metric().Name; name != "nDCG@2" {
+		t.Errorf("metric = %q, want nDCG@2", name)
+	}
+	embedder, err := NewBaselineEmbedder(EmbedderConfig{Backend: BackendHashing})
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := task.Run(context.Background(), []Embedder{embedder})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := results[embedder.Name()]
+	want := []SubMetric{{"Recall@2", 1}, {"MRR@2", 1}, {"MAP", 1}}
+	if result.Metric != 1 || !reflect.DeepEqual(result.SubMetrics, want) {
+		t.Errorf("result = %v %+v, want 1 %+v", result.Metric, result.SubMetrics, want)
+	}
+	if len(result.Items) != 2 || result.Items[0].Predicted != "d2" || result.Items[1].Predicted != "d3" {
+		t.Errorf("items = %+v, want top hits d2 and d3", result.Items)
+	}
+}
@@ -0,0 +1,80 @@
+package probes
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// RunTask runs task once per embedder, so every result carries its own
+// duration and embedding call counts, and a bootstrap confidence interval
+// when the task supports one. Each result is audited against the texts the
+// task actually embedded; see auditResult.
+func RunTask(ctx context.Context, task Task, embedders []Embedder) (map[string]TaskResult, error) {
+	results := make(map[string]TaskResult)
+	for _, embedder := range embedders {
+		counter := &recordingEmbedder{Embedder: embedder, seen: make(map[string]bool)}
+		start := time.Now()
+		modelResults, err := task.Run(ctx, []Embedder{counter})
+		if err != nil {
+			return nil, err
+		}
+		result, ok := modelResults[embedder.Name()]
+		if !ok {
+			return nil, fmt.Errorf("task returned no result for %s", embedder.Name())
+		}
+		result.Duration = time.Since(start)
+		result.EmbedCalls, result.EmbeddedTexts = counter.counts()
+		result.CI = BootstrapCI(task, result.Items)
+		result.AuditFailures = auditResult(task, result, counter.embedded())
+		results[embedder.Name()] = result
+	}
+	return results, nil
+}
+
+// recordingEmbedder counts the calls made through it and records the
+// distinct texts embedded, for the audit.
+type recordingEmbedder struct {
+	Embedder
+	mu    sync.Mutex
+	calls int
+	texts int
+	seen  map[string]bool
+}
+
+func (e *recordingEmbedder) Unwrap() Embedder {
+	return e.Embedder
+}
+
+func (e *recordingEmbedder) Embed(ctx context.Context, text string) ([]float64, error) {
+	e.record([]string{text})
+	return e.Embedder.Embed(ctx, text)
+}
+
+func (e *recordingEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float64, error) {
+	e.record(texts)
+	return e.Embedder.EmbedBatch(ctx, texts)
+}
+
+func (e *recordingEmbedder) record(texts []string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.calls++
+	e.texts += len(texts)
+	for _, text := range texts {
+		e.seen[text] = true
+	}
+}
+
+func (e *recordingEmbedder) counts() (calls, texts int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.calls, e.texts
+}
+
+func (e *recordingEmbedder) embedded() map[string]bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.seen
+}
@@ -1,6 +1,7 @@
 package probes
 
 import (
+	"context"
 	"fmt"
 )
 
@@ -10,11 +11,36 @@ func (t *semanticMetricEvidenceTask) Name() string {
 	return "Semantic Metric Evidence Task"
 }
 
-func (t *semanticMetricEvidenceTask) MetricName() string {
-	return "Weighted Similarity"
+func (t *semanticMetricEvidenceTask) Category() string {
+	return "Evidence"
 }
 
-func (t *semanticMetricEvidenceTask) Run(models []string) (map[string]TaskResult, error) {
+func (t *semanticMetricEvidenceTask) Metric() MetricDescriptor {
+	return MetricDescriptor{Name: "Weighted Similarity", Direction: HigherIsBetter, Tolerance: 1e-4, Min: -1, Max: 2}
+}
+
+func (t *semanticMetricEvidenceTask) ItemScoresAreSimilarities() bool {
+	return true
+}
+
+// MetricFromItems averages similarity for relevant items and one minus
+// similarity for irrelevant ones.
+func (t *semanticMetricEvidenceTask) MetricFromItems(items []ItemResult) float64 {
+	if len(items) == 0 {
+		return 0
+	}
+	var total float64
+	for _, item := range items {
+		if item.Actual == labelRelevant {
+			total += item.Score
+		} else {
+			total += 1.0 - item.Score
+		}
+	}
+	return total / float64(len(items))
+}
+
+func (t *semanticMetricEvidenceTask) Run(ctx context.Context, embedders []Embedder) (map[string]TaskResult, error) {
 	// Define the metric and evidence chunks with ground truth relevance
 	type Evidence struct {
 		text     string
@@ -46,27 +72,36 @@ func (t *semanticMetricEvidenceTask) Run(models []string) (map[string]TaskResult
 
 	results := make(map[string]TaskResult)
 
-	for _, model := range models {
+	for _, embedder := range embedders {
+		model := embedder.Name()
 		fmt.Printf("Model: %s\n", model)
-		metricEmb, err := getEmbedding(model, metric)
+		texts := []string{metric}
+		for _, evidence := range evidenceChunks {
+			texts = append(texts, evidence.text)
+		}
+		embeddings, err := embedder.EmbedBatch(ctx, texts)
 		if err != nil {
-			return nil, fmt.Errorf("error getting embedding for metric: %v", err)
+			return nil, fmt.Errorf("error getting embeddings: %v", err)
 		}
+		metricEmb := embeddings[0]
 
+		var items []ItemResult
 		var totalSimilarity float64
 		var relevantCount, irrelevantCount int
 		for i, evidence := range evidenceChunks {
-			evidenceEmb, err := getEmbedding(model, evidence.text)
-			if err != nil {
-				return nil, fmt.Errorf("error getting embedding for evidence %d: %v", i+1, err)
-			}
-
-			sim, err := cosineSimilarity(metricEmb, evidenceEmb)
+			sim, err := cosineSimilarity(metricEmb, embeddings[i+1])
 			if err != nil {
 				return nil, fmt.Errorf("error computing similarity for evidence %d: %v", i+1, err)
 			}
 
 			fmt.Printf("Evidence %d: Similarity=%.4f, Relevant=%v\n", i+1, sim, evidence.relevant)
+			items = append(items, ItemResult{
+				ID:       fmt.Sprintf("evidence-%d", i+1),
+				InputIDs: []string{"metric", fmt.Sprintf("evidence-%d", i+1)},
+				Texts:    []string{metric, evidence.text},
+				Score:    sim,
+				Actual:   relevanceLabel(evidence.relevant),
+			})
 			if evidence.relevant {
 				totalSimilarity += sim
 				relevantCount++
@@ -78,30 +113,12 @@ func (t *semanticMetricEvidenceTask) Run(models []string) (map[string]TaskResult
 
 		weightedSimilarity := totalSimilarity / float64(len(evidenceChunks))
 		fmt.Printf("Weighted Average Similarity: %.4f\n", weightedSimilarity)
-		results[model] = TaskResult{Metric: weightedSimilarity}
-	}
-
-	// Determine winner
-	maxSimilarity := -1.0
-	var winner string
-	for model, result := range results {
-		if result.Metric > maxSimilarity {
-			maxSimilarity = result.Metric
-			winner = model
-		}
-	}
-	for model := range results {
-		if model == winner {
-			result := results[model]
-			result.Winner = model
-			results[model] = result
-		}
+		results[model] = TaskResult{Metric: weightedSimilarity, SubMetrics: relevanceSubMetrics(items), Items: items}
 	}
 
 	return results, nil
 }
 
 func init() {
-	fmt.Println("Registering Semantic Metric Evidence Task")
 	RegisterTask(&semanticMetricEvidenceTask{})
 }
@@ -0,0 +1,203 @@
+package probes
+
+import (
+	"math"
+	"math/rand/v2"
+	"sort"
+)
+
+const (
+	// SignificanceLevel is the p-value at or above which a winner is marked
+	// not significant.
+	SignificanceLevel = 0.05
+	// BootstrapSamples is the number of resamples behind each confidence
+	// interval.
+	BootstrapSamples = 2000
+	// PermutationSamples is the number of random relabellings used when
+	// there are too many items to enumerate every one.
+	PermutationSamples = 10000
+	// maxExactPermutationItems bounds exhaustive enumeration at 2^n
+	// relabellings.
+	maxExactPermutationItems = 14
+	// significanceSeed fixes the resampling so reruns report the same
+	// intervals and p-values.
+	significanceSeed = 1
+)
+
+// ItemMetric is implemented by tasks whose headline metric can be recomputed
+// from any subset of a model's items. Only such tasks get confidence
+// intervals and significance tests.
+type ItemMetric interface {
+	MetricFromItems(items []ItemResult) float64
+}
+
+// ConfidenceInterval is a two-sided 95% interval for a metric.
+type ConfidenceInterval struct {
+	Low  float64 `json:"low"`
+	High float64 `json:"high"`
+}
+
+// meanScore is the MetricFromItems of tasks whose metric averages item
+// scores.
+func meanScore(items []ItemResult) float64 {
+	if len(items) == 0 {
+		return 0
+	}
+	var total float64
+	for _, item := range items {
+		total += item.Score
+	}
+	return total / float64(len(items))
+}
+
+// BootstrapCI returns the 95% percentile bootstrap interval of the task's
+// metric over items. It returns nil when the task does not implement
+// ItemMetric or there are fewer than two items.
+func BootstrapCI(task Task, items []ItemResult) *ConfidenceInterval {
+	metric, ok := task.(ItemMetric)
+	if !ok || len(items) < 2 {
+		return nil
+	}
+	rng := rand.New(rand.NewPCG(significanceSeed, 0))
+	values := make([]float64, BootstrapSamples)
+	sample := make([]ItemResult, len(items))
+	for s := range values {
+		for i := range sample {
+			sample[i] = items[rng.IntN(len(items))]
+		}
+		values[s] = metric.MetricFromItems(sample)
+	}
+	sort.Float64s(values)
+	return &ConfidenceInterval{
+		Low:  percentile(values, 0.025),
+		High: percentile(values, 0.975),
+	}
+}
+
+// percentile returns the q-quantile of sorted values by linear
+// interpolation.
+func percentile(sorted []float64, q float64) float64 {
+	pos := q * float64(len(sorted)-1)
+	lo := int(math.Floor(pos))
+	hi := int(math.Ceil(pos))
+	return sorted[lo] + (pos-float64(lo))*(sorted[hi]-sorted[lo])
+}
+
+// PairedPermutationTest returns the one-sided p-value for model a beating
+// model b on the task's metric, both scored on the same items. Under the
+// null hypothesis the models are interchangeable, so each item's pair of
+// results is swapped between them; the p-value is the share of swaps under
+// which a leads b, in the metric's direction, by at least the observed
+// margin. All 2^n swaps are enumerated for small n, otherwise
+// PermutationSamples random ones are used. ok is false when the task does
+// not implement ItemMetric or the two item lists do not line up.
+func PairedPermutationTest(task Task, a, b []ItemResult) (p float64, ok bool) {
+	metric, isItemMetric := task.(ItemMetric)
+	if !isItemMetric || len(a) == 0 || len(a) != len(b) {
+		return 0, false
+	}
+	for i := range a {
+		if a[i].ID != b[i].ID {
+			return 0, false
+		}
+	}
+
+	sign := 1.0
+	if task.Metric().Direction == LowerIsBetter {
+		sign = -1
+	}
+	lead := func(a, b []ItemResult) float64 {
+		return sign * (metric.MetricFromItems(a) - metric.MetricFromItems(b))
+	}
+	observed := lead(a, b)
+	permA := make([]ItemResult, len(a))
+	permB := make([]ItemResult, len(b))
+	extreme := func(swap func(i int) bool) bool {
+		for i := range a {
+			if swap(i) {
+				permA[i], permB[i] = b[i], a[i]
+			} else {
+				permA[i], permB[i] = a[i], b[i]
+			}
+		}
+		return lead(permA, permB) >= observed-1e-12
+	}
+
+	count, total := 0, 0
+	if len(a) <= maxExactPermutationItems {
+		for mask := 0; mask < 1<<len(a); mask++ {
+			if extreme(func(i int) bool { return mask&(1<<i) != 0 }) {
+				count++
+			}
+			total++
+		}
+		return float64(count) / float64(total), true
+	}
+	rng := rand.New(rand.NewPCG(significanceSeed, 0))
+	for s := 0; s < PermutationSamples; s++ {
+		if extreme(func(int) bool { return rng.IntN(2) == 1 }) {
+			count++
+		}
+		total++
+	}
+	// Count the observed labelling itself so p is never zero.
+	return float64(count+1) / float64(total+1), true
+}
+
+// PairwiseTest is the paired permutation test of one model leading
+// another on a task.
+type PairwiseTest struct {
+	// Better is the model with the better metric, or the earlier model
+	// when the two are equal; Worse is the other.
+	Better string  `json:"better"`
+	Worse  string  `json:"worse"`
+	PValue float64 `json:"p_value"`
+}
+
+// TestSignificance runs a paired permutation test between every pair of
+// models with a valid result, storing them in outcome.Pairwise, each
+// oriented so Better leads. outcome.PValue is then the largest p-value of
+// the single winner's tests, so a winner is significant only if it beats
+// each other model. Tasks without ItemMetric are left untested.
+func TestSignificance(task Task, outcome Outcome, results map[string]TaskResult, models []string) Outcome {
+	if outcome.Control {
+		return outcome
+	}
+	metric := task.Metric()
+	var valid []string
+	for _, model := range models {
+		if result, ok := results[model]; ok && result.valid(metric) {
+			valid = append(valid, model)
+		}
+	}
+
+	// Orient each pair by the metric alone, ignoring the tie tolerance.
+	exact := MetricDescriptor{Direction: metric.Direction}
+	var pairwise []PairwiseTest
+	for i, a := range valid {
+		for _, b := range valid[i+1:] {
+			better, worse := a, b
+			if exact.Better(results[b].Metric, results[a].Metric) {
+				better, worse = b, a
+			}
+			p, ok := PairedPermutationTest(task, results[better].Items, results[worse].Items)
+			if !ok {
+				return outcome
+			}
+			pairwise = append(pairwise, PairwiseTest{Better: better, Worse: worse, PValue: p})
+		}
+	}
+	outcome.Pairwise = pairwise
+
+	winner := outcome.Winner()
+	worst, tested := 0.0, false
+	for _, test := range pairwise {
+		if test.Better == winner {
+			worst, tested = math.Max(worst, test.PValue), true
+		}
+	}
+	if tested {
+		outcome.PValue = &worst
+	}
+	return outcome
+}
@@ -0,0 +1,210 @@
+package probes
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// meanTask is an ItemMetric task whose metric is the mean item score.
+type meanTask struct {
+	direction Direction
+}
+
+func (t meanTask) Name() string { return "mean" }
+
+func (t meanTask) Metric() MetricDescriptor {
+	return MetricDescriptor{Name: "Mean", Direction: t.direction, Min: 0, Max: 1}
+}
+
+func (t meanTask) Run(ctx context.Context, embedders []Embedder) (map[string]TaskResult, error) {
+	return nil, nil
+}
+
+func (t meanTask) MetricFromItems(items []ItemResult) float64 {
+	return meanScore(items)
+}
+
+// opaqueTask does not implement ItemMetric.
+type opaqueTask struct{}
+
+func (opaqueTask) Name() string { return "opaque" }
+
+func (opaqueTask) Metric() MetricDescriptor { return accuracyMetric }
+
+func (opaqueTask) Run(ctx context.Context, embedders []Embedder) (map[string]TaskResult, error) {
+	return nil, nil
+}
+
+// scoredItems returns one item per score, numbered from zero.
+func scoredItems(scores ...float64) []ItemResult {
+	items := make([]ItemResult, len(scores))
+	for i, score := range scores {
+		items[i] = ItemResult{ID: fmt.Sprint(i), Score: score}
+	}
+	return items
+}
+
+func constantItems(n int, score float64) []ItemResult {
+	scores := make([]float64, n)
+	for i := range scores {
+		scores[i] = score
+	}
+	return scoredItems(scores...)
+}
+
+func TestPairedPermutationTest(t *testing.T) {
+	higher := meanTask{HigherIsBetter}
+	lower := meanTask{LowerIsBetter}
+	tests := []struct {
+		name  string
+		task  Task
+		a, b  []ItemResult
+		want  float64
+		valid bool
+	}{
+		{"identical", higher, scoredItems(0.2, 0.5, 0.9), scoredItems(0.2, 0.5, 0.9), 1, true},
+		{"dominant on 5 items", higher, constantItems(5, 1), constantItems(5, 0), 1.0 / 32, true},
+		{"dominated on 5 items", higher, constantItems(5, 0), constantItems(5, 1), 1, true},
+		{"dominant on 3 items", higher, constantItems(3, 1), constantItems(3, 0), 1.0 / 8, true},
+		{"lower is better", lower, constantItems(5, 0), constantItems(5, 1), 1.0 / 32, true},
+		// Only swapping neither item or just the tied one keeps a's lead.
+		{"one tie", higher, scoredItems(1, 0.5), scoredItems(0, 0.5), 2.0 / 4, true},
+		{"not an item metric", opaqueTask{}, constantItems(3, 1), constantItems(3, 0), 0, false},
+		{"length mismatch", higher, constantItems(3, 1), constantItems(2, 0), 0, false},
+		{"no items", higher, nil, nil, 0, false},
+		{"ids differ", higher, scoredItems(1), []ItemResult{{ID: "other"}}, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, ok := PairedPermutationTest(tt.task, tt.a, tt.b)
+			if ok != tt.valid {
+				t.Fatalf("ok = %v, want %v", ok, tt.valid)
+			}
+			if math.Abs(p-tt.want) > 1e-12 {
+				t.Errorf("p = %v, want %v", p, tt.want)
+			}
+		})
+	}
+}
+
+func TestPairedPermutationTestSampled(t *testing.T) {
+	n := maxExactPermutationItems + 6
+	p, ok := PairedPermutationTest(meanTask{HigherIsBetter}, constantItems(n, 1), constantItems(n, 0))
+	if !ok {
+		t.Fatal("test was not run")
+	}
+	// Almost no random swap matches the observed lead, so p is about
+	// 1/(PermutationSamples+1) and never zero.
+	if p <= 0 || p > 3.0/PermutationSamples {
+		t.Errorf("p = %v, want about %v", p, 1.0/(PermutationSamples+1))
+	}
+	again, _ := PairedPermutationTest(meanTask{HigherIsBetter}, constantItems(n, 1), constantItems(n, 0))
+	if again != p {
+		t.Errorf("rerun gave p = %v, want %v", again, p)
+	}
+}
+
+func TestBootstrapCI(t *testing.T) {
+	task := meanTask{HigherIsBetter}
+	tests := []struct {
+		name    string
+		task    Task
+		items   []ItemResult
+		wantNil bool
+		low     float64
+		high    float64
+		exact   bool
+	}{
+		{"not an item metric", opaqueTask{}, scoredItems(0, 1), true, 0, 0, false},
+		{"single item", task, scoredItems(1), true, 0, 0, false},
+		{"constant", task, constantItems(10, 0.7), false, 0.7, 0.7, true},
+		{"spread", task, scoredItems(0, 0.25, 0.5, 0.75, 1, 0, 0.25, 0.5, 0.75, 1), false, 0, 1, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ci := BootstrapCI(tt.task, tt.items)
+			if tt.wantNil {
+				if ci != nil {
+					t.Errorf("got %+v, want nil", *ci)
+				}
+				return
+			}
+			if ci == nil {
+				t.Fatal("got nil interval")
+			}
+			if tt.exact {
+				if math.Abs(ci.Low-tt.low) > 1e-12 || math.Abs(ci.High-tt.high) > 1e-12 {
+					t.Errorf("interval = %+v, want [%v, %v]", *ci, tt.low, tt.high)
+				}
+				return
+			}
+			metric := meanScore(tt.items)
+			if ci.Low < tt.low || ci.High > tt.high || ci.Low > metric || ci.High < metric || ci.Low == ci.High {
+				t.Errorf("interval = %+v does not surround %v within [%v, %v]", *ci, metric, tt.low, tt.high)
+			}
+			if again := BootstrapCI(tt.task, tt.items); *again != *ci {
+				t.Errorf("rerun gave %+v, want %+v", *again, *ci)
+			}
+		})
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	sorted := []float64{0, 10, 20, 30, 40}
+	tests := []struct {
+		q    float64
+		want float64
+	}{
+		{0, 0},
+		{0.5, 20},
+		{0.625, 25},
+		{1, 40},
+	}
+	for _, tt := range tests {
+		if got := percentile(sorted, tt.q); math.Abs(got-tt.want) > 1e-12 {
+			t.Errorf("percentile(%v) = %v, want %v", tt.q, got, tt.want)
+		}
+	}
+}
+
+func TestTestSignificancePairwise(t *testing.T) {
+	task := meanTask{HigherIsBetter}
+	results := map[string]TaskResult{
+		"a": {Metric: 0.4, Items: scoredItems(0.4, 0.4, 0.4, 0.4, 0.4)},
+		"b": {Metric: 1, Items: constantItems(5, 1)},
+		"c": {Metric: 0, Items: constantItems(5, 0)},
+		// Out of range, so neither tested nor a winner.
+		"d": {Metric: 2, Items: constantItems(5, 2)},
+	}
+	models := []string{"a", "b", "c", "d"}
+	outcome := TestSignificance(task, SelectWinners(task.Metric(), results, models), results, models)
+
+	want := []PairwiseTest{
+		{Better: "b", Worse: "a", PValue: 1.0 / 32},
+		{Better: "a", Worse: "c", PValue: 1.0 / 32},
+		{Better: "b", Worse: "c", PValue: 1.0 / 32},
+	}
+	if !reflect.DeepEqual(outcome.Pairwise, want) {
+		t.Errorf("Pairwise = %+v, want %+v", outcome.Pairwise, want)
+	}
+	if outcome.Winner() != "b" || outcome.PValue == nil || *outcome.PValue != 1.0/32 || !outcome.Significant() {
+		t.Errorf("outcome = %v, want significant winner b with p=1/32", outcome)
+	}
+
+	// A winner that leads the runner-up on one item of five is not
+	// significant: only swaps keeping that item count, half of them.
+	results["a"] = TaskResult{Metric: 0.9, Items: scoredItems(1, 1, 1, 1, 0.5)}
+	outcome = TestSignificance(task, SelectWinners(task.Metric(), results, models), results, models)
+	if outcome.Significant() || *outcome.PValue != 0.5 {
+		t.Errorf("outcome = %v, want not significant with p=0.5", outcome)
+	}
+
+	tied := map[string]TaskResult{"a": results["c"], "b": results["c"]}
+	outcome = TestSignificance(task, SelectWinners(task.Metric(), tied, models), tied, models)
+	if outcome.PValue != nil || len(outcome.Pairwise) != 1 || outcome.Pairwise[0].PValue != 1 {
+		t.Errorf("tied outcome = %+v, want one untested-winner pair with p=1", outcome)
+	}
+}
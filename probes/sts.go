@@ -0,0 +1,132 @@
+package probes
+
+import (
+	"context"
+	"fmt"
+)
+
+// STSTaskConfig declares a semantic textual similarity task in config.json.
+type STSTaskConfig struct {
+	Name string `json:"name"`
+	// File is a .tsv or .jsonl pair file, as read by LoadPairs, in which
+	// every pair has a gold score.
+	File string `json:"file"`
+}
+
+// RegisterSTSTasks loads each configured STS file and registers a task for
+// it.
+func RegisterSTSTasks(configs []STSTaskConfig) error {
+	for _, cfg := range configs {
+		if cfg.Name == "" {
+			return fmt.Errorf("STS task for %s has no name", cfg.File)
+		}
+		pairs, err := LoadPairs(cfg.File)
+		if err != nil {
+			return err
+		}
+		task, err := NewSTSTask(cfg.Name, pairs)
+		if err != nil {
+			return fmt.Errorf("%s: %v", cfg.File, err)
+		}
+		if err := registerUnique(task); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// stsTask scores each model by how well the cosine similarity of each pair
+// ranks the pairs in the order of their gold scores, as MTEB's STS tasks do.
+// Unlike mean similarity, the correlation does not reward a model for
+// placing every text close together.
+type stsTask struct {
+	name  string
+	pairs []Pair
+}
+
+// NewSTSTask returns a task reporting the Spearman correlation between
+// cosine similarity and gold score, with Pearson as a sub-metric. Every pair
+// must have a score.
+func NewSTSTask(name string, pairs []Pair) (Task, error) {
+	if len(pairs) < 2 {
+		return nil, fmt.Errorf("STS task %q needs at least two pairs", name)
+	}
+	for _, pair := range pairs {
+		if pair.Score == nil {
+			return nil, fmt.Errorf("STS task %q: %s has no gold score", name, pair.ID)
+		}
+	}
+	return &stsTask{name: name, pairs: pairs}, nil
+}
+
+func (t *stsTask) Name() string {
+	return t.name
+}
+
+func (t *stsTask) Category() string {
+	return "STS"
+}
+
+func (t *stsTask) Metric() MetricDescriptor {
+	return MetricDescriptor{Name: "Spearman Correlation", Direction: HigherIsBetter, Tolerance: 1e-4, Min: -1, Max: 1}
+}
+
+func (t *stsTask) ItemScoresAreSimilarities() bool {
+	return true
+}
+
+func (t *stsTask) MetricFromItems(items []ItemResult) float64 {
+	sims := make([]float64, len(items))
+	gold := make([]float64, len(items))
+	for i, item := range items {
+		sims[i], gold[i] = item.Score, *item.Gold
+	}
+	return spearman(sims, gold)
+}
+
+func (t *stsTask) Run(ctx context.Context, embedders []Embedder) (map[string]TaskResult, error) {
+	gold := make([]float64, len(t.pairs))
+	texts := make([]string, 0, 2*len(t.pairs))
+	for i, pair := range t.pairs {
+		gold[i] = *pair.Score
+		texts = append(texts, pair.TextA, pair.TextB)
+	}
+
+	results := make(map[string]TaskResult)
+
+	for _, embedder := range embedders {
+		model := embedder.Name()
+		fmt.Printf("Model: %s\n", model)
+
+		embeddings, err := embedder.EmbedBatch(ctx, texts)
+		if err != nil {
+			return nil, fmt.Errorf("error getting embeddings: %v", err)
+		}
+
+		sims := make([]float64, len(t.pairs))
+		var items []ItemResult
+		for i, pair := range t.pairs {
+			sim, err := cosineSimilarity(embeddings[2*i], embeddings[2*i+1])
+			if err != nil {
+				return nil, fmt.Errorf("error computing similarity for %s: %v", pair.ID, err)
+			}
+			sims[i] = sim
+			fmt.Printf("%s: Similarity=%.4f, Gold=%.2f\n", pair.ID, sim, gold[i])
+			items = append(items, ItemResult{
+				ID:       pair.ID,
+				InputIDs: []string{pair.ID + "-a", pair.ID + "-b"},
+				Texts:    []string{pair.TextA, pair.TextB},
+				Score:    sim,
+				Gold:     &gold[i],
+			})
+		}
+
+		rho := spearman(sims, gold)
+		r := pearson(sims, gold)
+		fmt.Printf("Spearman: %.4f, Pearson: %.4f\n", rho, r)
+		subMetrics := append([]SubMetric{{"Pearson Correlation", r}}, similarityRangeSubMetrics(items)...)
+		results[model] = TaskResult{Metric: rho, SubMetrics: subMetrics, Items: items}
+	}
+
+	return results, nil
+}
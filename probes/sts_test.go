@@ -0,0 +1,76 @@
+package probes
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRanks(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		want   []float64
+	}{
+		{"distinct", []float64{3, 1, 2}, []float64{3, 1, 2}},
+		{"tie in the middle", []float64{10, 20, 20, 30}, []float64{1, 2.5, 2.5, 4}},
+		{"all equal", []float64{5, 5, 5}, []float64{2, 2, 2}},
+		{"two ties", []float64{2, 1, 2, 1}, []float64{3.5, 1.5, 3.5, 1.5}},
+		{"empty", nil, []float64{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ranks(tt.values); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ranks = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCorrelations(t *testing.T) {
+	tests := []struct {
+		name              string
+		x, y              []float64
+		pearson, spearman float64
+	}{
+		{"linear", []float64{1, 2, 3}, []float64{2, 4, 6}, 1, 1},
+		{"reversed", []float64{1, 2, 3}, []float64{6, 4, 2}, -1, -1},
+		{"one swap", []float64{1, 2, 3}, []float64{1, 3, 2}, 0.5, 0.5},
+		// Monotone but not linear: only the ranks correlate perfectly.
+		{"monotone", []float64{1, 2, 3}, []float64{1, 2, 10}, 27 / math.Sqrt(876), 1},
+		// Ranks 1, 2.5, 2.5, 4 against 1, 2, 3, 4: 4.5/sqrt(4.5*5).
+		{"tie in x", []float64{1, 2, 2, 3}, []float64{1, 2, 3, 4}, 4.5 / math.Sqrt(4.5*5), 4.5 / math.Sqrt(4.5*5)},
+		{"constant", []float64{1, 1, 1}, []float64{1, 2, 3}, 0, 0},
+		{"empty", nil, nil, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pearson(tt.x, tt.y); math.Abs(got-tt.pearson) > 1e-12 {
+				t.Errorf("pearson = %v, want %v", got, tt.pearson)
+			}
+			if got := spearman(tt.x, tt.y); math.Abs(got-tt.spearman) > 1e-12 {
+				t.Errorf("spearman = %v, want %v", got, tt.spearman)
+			}
+		})
+	}
+}
+
+func TestNewSTSTaskErrors(t *testing.T) {
+	score := 1.0
+	tests := []struct {
+		name  string
+		pairs []Pair
+		err   string
+	}{
+		{"one pair", []Pair{{ID: "p1", Score: &score}}, "needs at least two pairs"},
+		{"missing score", []Pair{{ID: "p1", Score: &score}, {ID: "p2"}}, "p2 has no gold score"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewSTSTask("sts", tt.pairs); err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("err = %v, want it to contain %q", err, tt.err)
+			}
+		})
+	}
+}
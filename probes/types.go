@@ -1,61 +1,136 @@
 package probes
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
-	"io"
 	"math"
-	"net/http"
+	"time"
 )
 
 type Task interface {
 	Name() string
-	MetricName() string
-	Run(models []string) (map[string]TaskResult, error)
+	// Metric describes the value reported in TaskResult.Metric.
+	Metric() MetricDescriptor
+	// Run evaluates every embedder. Embedding calls fail once ctx is
+	// cancelled, so tasks stop promptly by returning those errors.
+	Run(ctx context.Context, embedders []Embedder) (map[string]TaskResult, error)
 }
 
+// TaskResult holds one model's score on a task. Winners are chosen by the
+// runner with SelectWinners, not by the task.
 type TaskResult struct {
-	Metric float64
-	Winner string
+	Metric float64 `json:"metric"`
+	// SubMetrics are secondary measurements, in the order they should be
+	// reported.
+	SubMetrics []SubMetric `json:"sub_metrics,omitempty"`
+	// Items records every scored item so reports can show how the metric
+	// was reached.
+	Items []ItemResult `json:"items,omitempty"`
+	// CI is the bootstrap confidence interval of Metric, set by RunTask for
+	// tasks that implement ItemMetric.
+	CI *ConfidenceInterval `json:"ci,omitempty"`
+	// AuditFailures describes why the result looks degenerate, e.g. a text
+	// that was compared but never embedded. Set by RunTask; a result with
+	// failures never wins.
+	AuditFailures []string `json:"audit_failures,omitempty"`
+
+	// Duration and EmbedCalls are filled in by RunTask.
+	Duration time.Duration `json:"duration_ns"`
+	// EmbedCalls counts Embed and EmbedBatch calls; EmbeddedTexts counts
+	// the texts passed to them.
+	EmbedCalls    int `json:"embed_calls"`
+	EmbeddedTexts int `json:"embedded_texts"`
 }
 
-var TaskRegistry []Task
+// SubMetric is a named secondary measurement.
+type SubMetric struct {
+	Name  string  `json:"name"`
+	Value float64 `json:"value"`
+}
 
-func RegisterTask(task Task) {
-	TaskRegistry = append(TaskRegistry, task)
+// ItemResult is one scored unit of a task, such as an evidence chunk
+// compared against a query or a pair of phrases.
+type ItemResult struct {
+	ID string `json:"id"`
+	// InputIDs and Texts list the compared inputs in the same order.
+	InputIDs []string `json:"input_ids,omitempty"`
+	Texts    []string `json:"texts,omitempty"`
+	Score    float64  `json:"score"`
+	// Predicted and Actual are labels for classification tasks, empty
+	// otherwise.
+	Predicted string `json:"predicted,omitempty"`
+	Actual    string `json:"actual,omitempty"`
+	// Gold is the reference grade or score the item's Score is ranked
+	// against, nil when the task has none.
+	Gold *float64 `json:"gold,omitempty"`
 }
 
-func getEmbedding(model, text string) ([]float64, error) {
-	url := "http://localhost:11434/api/embeddings"
-	payload := map[string]string{
-		"model":  model,
-		"prompt": text,
-	}
-	body, err := json.Marshal(payload)
-	if err != nil {
-		return nil, fmt.Errorf("error marshaling payload: %v", err)
+// SubMetric returns the value of the named sub-metric.
+func (r TaskResult) SubMetric(name string) (float64, bool) {
+	for _, m := range r.SubMetrics {
+		if m.Name == name {
+			return m.Value, true
+		}
 	}
+	return 0, false
+}
 
-	resp, err := http.Post(url, "application/json", bytes.NewBuffer(body))
-	if err != nil {
-		return nil, fmt.Errorf("error making request: %v", err)
+const (
+	labelRelevant   = "relevant"
+	labelIrrelevant = "irrelevant"
+)
+
+func relevanceLabel(relevant bool) string {
+	if relevant {
+		return labelRelevant
 	}
-	defer resp.Body.Close()
+	return labelIrrelevant
+}
 
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("error reading response: %v", err)
+// relevanceSubMetrics summarises how far apart a model places relevant and
+// irrelevant items, using each item's Actual label and similarity Score.
+func relevanceSubMetrics(items []ItemResult) []SubMetric {
+	var relevantSum, irrelevantSum float64
+	var relevantCount, irrelevantCount int
+	for _, item := range items {
+		if item.Actual == labelRelevant {
+			relevantSum += item.Score
+			relevantCount++
+		} else {
+			irrelevantSum += item.Score
+			irrelevantCount++
+		}
+	}
+	var metrics []SubMetric
+	if relevantCount > 0 {
+		metrics = append(metrics, SubMetric{"Mean Relevant Similarity", relevantSum / float64(relevantCount)})
+	}
+	if irrelevantCount > 0 {
+		metrics = append(metrics, SubMetric{"Mean Irrelevant Similarity", irrelevantSum / float64(irrelevantCount)})
+	}
+	if relevantCount > 0 && irrelevantCount > 0 {
+		metrics = append(metrics, SubMetric{"Similarity Gap", relevantSum/float64(relevantCount) - irrelevantSum/float64(irrelevantCount)})
 	}
+	return metrics
+}
 
-	var result struct {
-		Embedding []float64 `json:"embedding"`
+// similarityRangeSubMetrics reports the spread of item scores.
+func similarityRangeSubMetrics(items []ItemResult) []SubMetric {
+	if len(items) == 0 {
+		return nil
 	}
-	if err := json.Unmarshal(respBody, &result); err != nil {
-		return nil, fmt.Errorf("error unmarshaling response: %v", err)
+	lo, hi := items[0].Score, items[0].Score
+	for _, item := range items[1:] {
+		lo = math.Min(lo, item.Score)
+		hi = math.Max(hi, item.Score)
 	}
+	return []SubMetric{{"Min Similarity", lo}, {"Max Similarity", hi}}
+}
+
+var TaskRegistry []Task
 
-	return result.Embedding, nil
+func RegisterTask(task Task) {
+	TaskRegistry = append(TaskRegistry, task)
 }
 
 func cosineSimilarity(a, b []float64) (float64, error) {
@@ -78,3 +153,31 @@ func cosineSimilarity(a, b []float64) (float64, error) {
 
 	return dotProduct / (normA * normB), nil
 }
+
+// normalizeAll scales each vector to unit length so dot products are cosine
+// similarities. All vectors must have the same dimension.
+func normalizeAll(vectors [][]float64) ([][]float64, error) {
+	normalized := make([][]float64, len(vectors))
+	for i, v := range vectors {
+		if len(v) != len(vectors[0]) {
+			return nil, fmt.Errorf("embedding dimensions do not match: %d vs %d", len(v), len(vectors[0]))
+		}
+		norm := math.Sqrt(dot(v, v))
+		if norm == 0 {
+			return nil, fmt.Errorf("zero magnitude vector")
+		}
+		normalized[i] = make([]float64, len(v))
+		for j, x := range v {
+			normalized[i][j] = x / norm
+		}
+	}
+	return normalized, nil
+}
+
+func dot(a, b []float64) float64 {
+	var total float64
+	for i := range a {
+		total += a[i] * b[i]
+	}
+	return total
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	probes "embedding-probes/probes"
+)
+
+// printTaskSummary prints each model's sub-metrics, timing and embedding
+// call counts after a task finishes, followed by any audit failures.
+func printTaskSummary(embedders []probes.Embedder, results map[string]probes.TaskResult) {
+	for _, embedder := range embedders {
+		result := results[embedder.Name()]
+		var b strings.Builder
+		fmt.Fprintf(&b, "%s: %d embedding calls (%d texts) in %s", embedder.Name(),
+			result.EmbedCalls, result.EmbeddedTexts, result.Duration.Round(time.Millisecond))
+		if result.CI != nil {
+			fmt.Fprintf(&b, ", 95%% CI [%.4f, %.4f]", result.CI.Low, result.CI.High)
+		}
+		for _, m := range result.SubMetrics {
+			fmt.Fprintf(&b, ", %s=%.4f", m.Name, m.Value)
+		}
+		fmt.Println(b.String())
+		for _, failure := range result.AuditFailures {
+			fmt.Printf("  AUDIT FAILED: %s\n", failure)
+		}
+	}
+}
+
+// printResultsTable prints one row per task in the run and one metric
+// column per model, in config order.
+func printResultsTable(record probes.RunRecord) {
+	header := append([]string{"Task", "Task Name", "Metric"}, record.Models...)
+	header = append(header, "Winner")
+
+	var rows [][]string
+	for _, task := range record.Tasks {
+		row := []string{fmt.Sprintf("%d", task.Number), task.Name, task.Metric.Name}
+		for _, name := range record.Models {
+			if task.Cancelled {
+				row = append(row, "cancelled")
+			} else {
+				row = append(row, probes.FormatScore(task.Results[name]))
+			}
+		}
+		if task.Cancelled {
+			row = append(row, "cancelled")
+		} else {
+			row = append(row, task.Outcome.String())
+		}
+		rows = append(rows, row)
+	}
+
+	fmt.Println("\nFinal Results Table:")
+	printTable(header, rows)
+}
+
+// printTable prints rows under header with columns sized to their widest
+// entry.
+func printTable(header []string, rows [][]string) {
+	widths := make([]int, len(header))
+	for i, cell := range header {
+		widths[i] = len(cell)
+	}
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], len(cell))
+		}
+	}
+
+	printRow := func(cells []string) {
+		var b strings.Builder
+		b.WriteString("|")
+		for i, cell := range cells {
+			fmt.Fprintf(&b, " %-*s |", widths[i], cell)
+		}
+		fmt.Fprintln(os.Stdout, b.String())
+	}
+	printRow(header)
+	var separator strings.Builder
+	separator.WriteString("|")
+	for _, width := range widths {
+		separator.WriteString(strings.Repeat("-", width+2) + "|")
+	}
+	fmt.Println(separator.String())
+	for _, row := range rows {
+		printRow(row)
+	}
+}
+
+// printReliability tallies outright task wins per model; ties count for
+// nobody, and baselines are left out. Wins that are not statistically
+// significant still count but are reported separately. With two models it
+// states which is more reliable; with more it prints a ranking.
+func printReliability(record probes.RunRecord) {
+	names := record.ModelNames()
+	wins, significant := record.Wins()
+
+	fmt.Printf("\nOverall Reliability:\n")
+	for _, name := range names {
+		fmt.Printf("%s wins: %d (%d significant)\n", name, wins[name], significant[name])
+	}
+	if len(names) < 2 {
+		return
+	}
+
+	ranked := append([]string(nil), names...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return wins[ranked[i]] > wins[ranked[j]]
+	})
+
+	if len(names) == 2 {
+		first, second := ranked[0], ranked[1]
+		if wins[first] > wins[second] {
+			fmt.Printf("%s is more reliable (%d vs. %d wins).\n", first, wins[first], wins[second])
+		} else {
+			fmt.Println("Both models are equally reliable.")
+		}
+		return
+	}
+
+	fmt.Println("\nRanking:")
+	rank := 0
+	for i, name := range ranked {
+		// Models with equal wins share a rank.
+		if i == 0 || wins[name] != wins[ranked[i-1]] {
+			rank = i + 1
+		}
+		fmt.Printf("%d. %s (%d wins)\n", rank, name, wins[name])
+	}
+	if wins[ranked[0]] > wins[ranked[1]] {
+		fmt.Printf("%s is the most reliable (%d wins).\n", ranked[0], wins[ranked[0]])
+	} else {
+		fmt.Println("No single model is the most reliable.")
+	}
+}